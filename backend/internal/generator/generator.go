@@ -0,0 +1,283 @@
+// Package generator creates new Sudoku puzzles with a unique solution.
+package generator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"sudoku/backend/internal/solver"
+)
+
+var (
+	// ErrInvalidSymmetry is returned for an unknown symmetry name.
+	ErrInvalidSymmetry = errors.New("invalid_symmetry")
+	// ErrInvalidBand is returned when the difficulty band is outside 1-10 or inverted.
+	ErrInvalidBand = errors.New("invalid_difficulty_band")
+	// ErrGenerationFailed is returned when no puzzle in the band was found within MaxAttempts.
+	ErrGenerationFailed = errors.New("generation_failed")
+)
+
+// Symmetry describes which cells are carved together.
+type Symmetry string
+
+// Supported symmetries.
+const (
+	SymmetryNone         Symmetry = "none"
+	SymmetryRotational   Symmetry = "rotational"   // 180° rotation
+	SymmetryDiagonal     Symmetry = "diagonal"     // reflection across the main diagonal
+	SymmetryMirror       Symmetry = "mirror"       // left-right reflection
+	SymmetryRotational90 Symmetry = "rotational90" // 90° rotation
+)
+
+// defaultMaxAttempts keeps a request to a few seconds. Narrow bands at levels the
+// techniques rarely produce (e.g. 7, which needs a swordfish or jellyfish) may still fail.
+const defaultMaxAttempts = 200
+
+// Options controls puzzle generation.
+type Options struct {
+	Symmetry      Symmetry
+	MinDifficulty int
+	MaxDifficulty int
+	// MaxAttempts bounds how many solutions are carved before giving up (default 200).
+	MaxAttempts int
+	// Rand is the randomness source; a time-seeded one is used when nil.
+	Rand *rand.Rand
+	// Workers carves that many attempts at once on separate goroutines. Zero or one
+	// runs them one at a time on the calling goroutine. The result for a given Rand does
+	// not depend on Workers.
+	Workers int
+}
+
+// Puzzle is a generated puzzle with its solution and rating.
+type Puzzle struct {
+	Givens     solver.Grid
+	Solution   solver.Grid
+	Difficulty int
+	SERating   float64
+	// Minimal is true when no single given can be removed without losing uniqueness.
+	// Symmetric carving often stops short of that.
+	Minimal bool
+}
+
+// Generate fills a random solution and carves givens under the chosen symmetry until the
+// puzzle rates inside the difficulty band.
+func Generate(ctx context.Context, opts Options) (Puzzle, error) {
+	if opts.Symmetry == "" {
+		opts.Symmetry = SymmetryNone
+	}
+	orbits, err := symmetryOrbits(opts.Symmetry)
+	if err != nil {
+		return Puzzle{}, err
+	}
+	if opts.MinDifficulty < 1 || opts.MaxDifficulty > 10 || opts.MinDifficulty > opts.MaxDifficulty {
+		return Puzzle{}, ErrInvalidBand
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	rng := opts.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	// Each attempt has its own source so that attempts can run in any order.
+	seeds := make([]int64, opts.MaxAttempts)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	attempt := func(i int) (Puzzle, bool, error) {
+		attemptRng := rand.New(rand.NewSource(seeds[i]))
+		solution := fillSolution(attemptRng)
+		givens, rating, err := carve(ctx, solution, orbits, opts.MaxDifficulty, attemptRng)
+		if err != nil || rating.Difficulty < opts.MinDifficulty {
+			return Puzzle{}, false, err
+		}
+		minimal, _ := solver.IsMinimal(givens)
+		return Puzzle{
+			Givens:     givens,
+			Solution:   solution,
+			Difficulty: rating.Difficulty,
+			SERating:   rating.SERating,
+			Minimal:    minimal,
+		}, true, nil
+	}
+
+	if opts.Workers > 1 {
+		return generateParallel(ctx, opts.Workers, opts.MaxAttempts, attempt)
+	}
+	for i := 0; i < opts.MaxAttempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return Puzzle{}, err
+		}
+		p, ok, err := attempt(i)
+		if err != nil {
+			return Puzzle{}, err
+		}
+		if ok {
+			return p, nil
+		}
+	}
+	return Puzzle{}, ErrGenerationFailed
+}
+
+// generateParallel runs attempts on workers goroutines and returns the first attempt, in
+// attempt order, that succeeded. Attempts after a success are skipped, but every
+// earlier one still finishes, so the result matches running them in order.
+func generateParallel(ctx context.Context, workers, attempts int, attempt func(int) (Puzzle, bool, error)) (Puzzle, error) {
+	var (
+		mu     sync.Mutex
+		next   int
+		best   = attempts
+		result Puzzle
+		first  error
+	)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				i := next
+				next++
+				stop := i >= best || first != nil
+				mu.Unlock()
+				if stop {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					mu.Lock()
+					first = err
+					mu.Unlock()
+					return
+				}
+
+				p, ok, err := attempt(i)
+				mu.Lock()
+				switch {
+				case err != nil:
+					if first == nil {
+						first = err
+					}
+				case ok && i < best:
+					best, result = i, p
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if first != nil {
+		return Puzzle{}, first
+	}
+	if best == attempts {
+		return Puzzle{}, ErrGenerationFailed
+	}
+	return result, nil
+}
+
+// carve removes symmetric groups of cells in random order, keeping each removal only if
+// the solution stays unique and the rating stays at or below maxDifficulty.
+func carve(ctx context.Context, solution solver.Grid, orbits [][]int, maxDifficulty int, rng *rand.Rand) (solver.Grid, solver.Rating, error) {
+	givens := solution
+	rating := solver.Rate(givens)
+
+	for _, k := range rng.Perm(len(orbits)) {
+		if err := ctx.Err(); err != nil {
+			return solver.Grid{}, solver.Rating{}, err
+		}
+
+		candidate := givens
+		for _, idx := range orbits[k] {
+			candidate[idx] = 0
+		}
+		if count, _ := solver.CountSolutions(candidate, 2); count != 1 {
+			continue
+		}
+		r := solver.Rate(candidate)
+		if r.Difficulty > maxDifficulty {
+			continue
+		}
+		givens, rating = candidate, r
+	}
+
+	return givens, rating, nil
+}
+
+// fillSolution builds a random complete grid by randomized backtracking.
+func fillSolution(rng *rand.Rand) solver.Grid {
+	var g solver.Grid
+	var rows, cols, boxes [9]uint16
+
+	var fill func(idx int) bool
+	fill = func(idx int) bool {
+		if idx == 81 {
+			return true
+		}
+		r := idx / 9
+		c := idx % 9
+		b := (r/3)*3 + (c / 3)
+		for _, k := range rng.Perm(9) {
+			digit := uint8(k + 1)
+			bit := uint16(1) << digit
+			if (rows[r]|cols[c]|boxes[b])&bit != 0 {
+				continue
+			}
+			g[idx] = digit
+			rows[r] |= bit
+			cols[c] |= bit
+			boxes[b] |= bit
+			if fill(idx + 1) {
+				return true
+			}
+			rows[r] &^= bit
+			cols[c] &^= bit
+			boxes[b] &^= bit
+		}
+		g[idx] = 0
+		return false
+	}
+
+	fill(0)
+	return g
+}
+
+// symmetryOrbits groups cells that must be carved together under a symmetry.
+func symmetryOrbits(sym Symmetry) ([][]int, error) {
+	var images func(r, c int) [][2]int
+	switch sym {
+	case SymmetryNone:
+		images = func(r, c int) [][2]int { return [][2]int{{r, c}} }
+	case SymmetryRotational:
+		images = func(r, c int) [][2]int { return [][2]int{{r, c}, {8 - r, 8 - c}} }
+	case SymmetryDiagonal:
+		images = func(r, c int) [][2]int { return [][2]int{{r, c}, {c, r}} }
+	case SymmetryMirror:
+		images = func(r, c int) [][2]int { return [][2]int{{r, c}, {r, 8 - c}} }
+	case SymmetryRotational90:
+		images = func(r, c int) [][2]int { return [][2]int{{r, c}, {c, 8 - r}, {8 - r, 8 - c}, {8 - c, r}} }
+	default:
+		return nil, ErrInvalidSymmetry
+	}
+
+	var orbits [][]int
+	var assigned [81]bool
+	for idx := 0; idx < 81; idx++ {
+		if assigned[idx] {
+			continue
+		}
+		var orbit []int
+		for _, rc := range images(idx/9, idx%9) {
+			other := rc[0]*9 + rc[1]
+			if !assigned[other] {
+				assigned[other] = true
+				orbit = append(orbit, other)
+			}
+		}
+		orbits = append(orbits, orbit)
+	}
+	return orbits, nil
+}
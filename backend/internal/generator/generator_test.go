@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"sudoku/backend/internal/solver"
+)
+
+func TestGenerateRespectsSymmetryAndUniqueness(t *testing.T) {
+	t.Parallel()
+
+	symmetries := []Symmetry{SymmetryNone, SymmetryRotational, SymmetryDiagonal, SymmetryMirror, SymmetryRotational90}
+	for i, sym := range symmetries {
+		p, err := Generate(context.Background(), Options{
+			Symmetry:      sym,
+			MinDifficulty: 1,
+			MaxDifficulty: 10,
+			Rand:          rand.New(rand.NewSource(int64(i + 1))),
+		})
+		if err != nil {
+			t.Fatalf("%s: generate: %v", sym, err)
+		}
+		if count, _ := solver.CountSolutions(p.Givens, 2); count != 1 {
+			t.Fatalf("%s: expected a unique solution, got %d", sym, count)
+		}
+
+		orbits, _ := symmetryOrbits(sym)
+		for _, orbit := range orbits {
+			for _, idx := range orbit[1:] {
+				if (p.Givens[idx] == 0) != (p.Givens[orbit[0]] == 0) {
+					t.Fatalf("%s: cells %d and %d break the symmetry", sym, orbit[0], idx)
+				}
+			}
+		}
+		for idx, v := range p.Givens {
+			if v != 0 && v != p.Solution[idx] {
+				t.Fatalf("%s: given at %d does not match the solution", sym, idx)
+			}
+		}
+	}
+}
+
+func TestGenerateStaysInBand(t *testing.T) {
+	t.Parallel()
+
+	p, err := Generate(context.Background(), Options{
+		Symmetry:      SymmetryRotational,
+		MinDifficulty: 1,
+		MaxDifficulty: 2,
+		Rand:          rand.New(rand.NewSource(42)),
+	})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if p.Difficulty > 2 {
+		t.Fatalf("expected difficulty at most 2, got %d", p.Difficulty)
+	}
+	if r := solver.Rate(p.Givens); r.Difficulty != p.Difficulty {
+		t.Fatalf("reported rating %d does not match %d", p.Difficulty, r.Difficulty)
+	}
+}
+
+func TestGenerateRejectsInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Generate(context.Background(), Options{Symmetry: "spiral", MinDifficulty: 1, MaxDifficulty: 10}); err != ErrInvalidSymmetry {
+		t.Fatalf("expected ErrInvalidSymmetry, got %v", err)
+	}
+	if _, err := Generate(context.Background(), Options{MinDifficulty: 6, MaxDifficulty: 3}); err != ErrInvalidBand {
+		t.Fatalf("expected ErrInvalidBand, got %v", err)
+	}
+}
+
+func TestGenerateWithWorkersMatchesSequential(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{Symmetry: SymmetryRotational, MinDifficulty: 1, MaxDifficulty: 3}
+	seq, par := opts, opts
+	seq.Rand = rand.New(rand.NewSource(7))
+	par.Rand = rand.New(rand.NewSource(7))
+	par.Workers = 4
+
+	a, err := Generate(context.Background(), seq)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	b, err := Generate(context.Background(), par)
+	if err != nil {
+		t.Fatalf("generate with workers: %v", err)
+	}
+	if a.Givens != b.Givens {
+		t.Fatalf("expected the same puzzle from the same seed:\n%s\n%s", a.Givens, b.Givens)
+	}
+}
+
+// BenchmarkGenerateWorkers generates in a band most attempts miss, one attempt at a time
+// and on every CPU.
+func BenchmarkGenerateWorkers(b *testing.B) {
+	counts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		counts = append(counts, n)
+	}
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := Generate(context.Background(), Options{
+					Symmetry:      SymmetryRotational,
+					MinDifficulty: 4,
+					MaxDifficulty: 5,
+					Rand:          rand.New(rand.NewSource(int64(i))),
+					Workers:       workers,
+				})
+				if err != nil && err != ErrGenerationFailed {
+					b.Fatalf("generate: %v", err)
+				}
+			}
+		})
+	}
+}
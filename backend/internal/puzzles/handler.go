@@ -2,16 +2,21 @@
 package puzzles
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"sudoku/backend/internal/auth"
 	"sudoku/backend/internal/httputil"
+	"sudoku/backend/internal/render"
 )
 
 // NewHandler creates a new HTTP handler for puzzles.
@@ -20,7 +25,10 @@ func NewHandler(service *Service) http.Handler {
 
 	r := chi.NewRouter()
 	r.Post("/validate", h.validate)
-	r.Post("/optimize", h.optimizeStub)
+	r.With(auth.RequireAuth).Post("/optimize", h.optimize)
+	r.Post("/import", h.importPuzzles)
+	r.With(auth.RequireAuth).Post("/import/bulk", h.bulkImport)
+	r.With(auth.RequireAuth).Post("/generate", h.generate)
 
 	r.Post("/", h.create)
 	r.With(auth.RequireAuth).Get("/mine", h.mine)
@@ -28,12 +36,17 @@ func NewHandler(service *Service) http.Handler {
 	r.With(auth.RequireAuth).Post("/{id}/publish", h.publish)
 	r.With(auth.RequireAuth).Delete("/{id}", h.deletePuzzle)
 	r.Get("/", h.list)
+	r.Get("/export", h.exportCollection)
+	r.Get("/booklet.pdf", h.booklet)
 	r.Get("/{id}", h.get)
+	r.Get("/{id}/export", h.export)
+	r.Get("/{id}/image.svg", h.image)
+	r.Get("/{id}/image.png", h.image)
 	r.Post("/{id}/complete", h.complete)
 	r.With(auth.RequireAuth).Get("/{id}/progress", h.getProgress)
 	r.With(auth.RequireAuth).Put("/{id}/progress", h.saveProgress)
 	r.With(auth.RequireAuth).Delete("/{id}/progress", h.clearProgress)
-	r.Get("/{id}/hint", h.hintStub)
+	r.Post("/{id}/hint", h.hint)
 	return r
 }
 
@@ -50,13 +63,72 @@ func (h *handler) validate(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.service.Validate(r.Context(), req)
 	if err != nil {
-		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
 		return
 	}
 
 	httputil.WriteJSON(w, http.StatusOK, resp)
 }
 
+// maxImportBytes bounds an import request body.
+const maxImportBytes = 4 << 20
+
+func (h *handler) importPuzzles(w http.ResponseWriter, r *http.Request) {
+	var req ImportRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportBytes)).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	resp, err := h.service.Import(r.Context(), req)
+	if err != nil {
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// bulkImport reads a puzzle file from the body, or from the "file" field of a multipart
+// form, and streams one JSON result per puzzle line as newline-delimited JSON. An error
+// after the first result ends the stream with an {"error": ...} line.
+func (h *handler) bulkImport(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid_file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	err := h.service.BulkImport(r.Context(), user.ID, body, func(result BulkImportResult) error {
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		_ = enc.Encode(map[string]any{"error": err.Error()})
+	}
+}
+
 func (h *handler) create(w http.ResponseWriter, r *http.Request) {
 	user := auth.UserFromContext(r.Context())
 	if user == nil {
@@ -124,6 +196,22 @@ func (h *handler) publish(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.service.Publish(r.Context(), uint(id64), user.ID)
 	if err != nil {
+		var notUnique *NotUniqueError
+		if errors.As(err, &notUnique) && notUnique.Ambiguity != nil {
+			httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+				"error":     err.Error(),
+				"ambiguity": notUnique.Ambiguity,
+			})
+			return
+		}
+		var duplicate *DuplicateError
+		if errors.As(err, &duplicate) {
+			httputil.WriteJSON(w, http.StatusConflict, map[string]any{
+				"error":       err.Error(),
+				"duplicateOf": duplicate.PuzzleID,
+			})
+			return
+		}
 		httputil.WriteError(w, httpStatusFromError(err), err.Error())
 		return
 	}
@@ -169,66 +257,227 @@ func (h *handler) mine(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) list(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
+	req, err := listRequestFromQuery(r.URL.Query())
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		req.UserID = &u.ID
+	}
 
+	resp, err := h.service.List(r.Context(), req)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// listRequestFromQuery reads the list filters, sort and page from query parameters.
+func listRequestFromQuery(q url.Values) (ListRequest, error) {
 	var difficulty *int
 	if d := q.Get("difficulty"); d != "" {
 		v, err := strconv.Atoi(d)
 		if err != nil {
-			httputil.WriteError(w, http.StatusBadRequest, "invalid_difficulty")
-			return
+			return ListRequest{}, errors.New("invalid_difficulty")
 		}
 		difficulty = &v
 	}
 
-	sort := q.Get("sort")
-	page := atoiOrDefault(q.Get("page"), 1)
-	pageSize := atoiOrDefault(q.Get("pageSize"), 20)
+	minSE, err := parseOptionalFloat(q.Get("minSe"))
+	if err != nil {
+		return ListRequest{}, errors.New("invalid_se_rating")
+	}
+	maxSE, err := parseOptionalFloat(q.Get("maxSe"))
+	if err != nil {
+		return ListRequest{}, errors.New("invalid_se_rating")
+	}
+
+	return ListRequest{
+		Difficulty:  difficulty,
+		MinSERating: minSE,
+		MaxSERating: maxSE,
+		Sort:        q.Get("sort"),
+		Page:        atoiOrDefault(q.Get("page"), 1),
+		PageSize:    atoiOrDefault(q.Get("pageSize"), 20),
+	}, nil
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request) {
+	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 0)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	if id64 == 0 {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	id := uint(id64)
 
 	var userID *uint
 	if u := auth.UserFromContext(r.Context()); u != nil {
 		userID = &u.ID
 	}
 
-	resp, err := h.service.List(r.Context(), ListRequest{
-		Difficulty: difficulty,
-		Sort:       sort,
-		Page:       page,
-		PageSize:   pageSize,
-		UserID:     userID,
-	})
+	resp, err := h.service.Get(r.Context(), id, userID)
 	if err != nil {
-		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
 		return
 	}
 
 	httputil.WriteJSON(w, http.StatusOK, resp)
 }
 
-func (h *handler) get(w http.ResponseWriter, r *http.Request) {
+func (h *handler) export(w http.ResponseWriter, r *http.Request) {
 	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 0)
-	if err != nil {
+	if err != nil || id64 == 0 {
 		httputil.WriteError(w, http.StatusBadRequest, "invalid_id")
 		return
 	}
-	if id64 == 0 {
+	h.writeExport(w, r, []uint{uint(id64)})
+}
+
+// exportCollection exports the puzzles listed in the comma-separated ids parameter.
+func (h *handler) exportCollection(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.writeExport(w, r, ids)
+}
+
+// parseIDs reads a comma-separated list of puzzle IDs.
+func parseIDs(raw string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id64, err := strconv.ParseUint(part, 10, 0)
+		if err != nil || id64 == 0 {
+			return nil, errors.New("invalid_ids")
+		}
+		ids = append(ids, uint(id64))
+	}
+	return ids, nil
+}
+
+// booklet renders a PDF of the puzzles listed in ids or, without ids, of the puzzles
+// List returns for the same filter parameters. perPage, paper and title set the layout.
+func (h *handler) booklet(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ids, err := parseIDs(q.Get("ids"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter, err := listRequestFromQuery(q)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var userID *uint
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		userID = &u.ID
+	}
+
+	file, err := h.service.Booklet(r.Context(), userID, BookletRequest{
+		IDs:     ids,
+		Filter:  filter,
+		Title:   q.Get("title"),
+		PerPage: atoiOrDefault(q.Get("perPage"), 0),
+		Paper:   q.Get("paper"),
+	})
+	if err != nil {
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
+		return
+	}
+
+	writeFile(w, file)
+}
+
+func (h *handler) writeExport(w http.ResponseWriter, r *http.Request, ids []uint) {
+	var userID *uint
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		userID = &u.ID
+	}
+
+	file, err := h.service.Export(r.Context(), ids, userID, r.URL.Query().Get("format"))
+	if err != nil {
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
+		return
+	}
+
+	writeFile(w, file)
+}
+
+// writeFile sends file as a download.
+func writeFile(w http.ResponseWriter, file ExportFile) {
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+file.Filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(file.Body)
+}
+
+// Cell widths accepted by the image endpoints, in pixels.
+const (
+	minImageCell = 16
+	maxImageCell = 128
+)
+
+// image renders the puzzle as SVG or PNG, depending on the path's extension. Query
+// parameters: solution=true, progress=true (signed-in users) and cell, the cell width in
+// pixels.
+func (h *handler) image(w http.ResponseWriter, r *http.Request) {
+	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 0)
+	if err != nil || id64 == 0 {
 		httputil.WriteError(w, http.StatusBadRequest, "invalid_id")
 		return
 	}
-	id := uint(id64)
+
+	q := r.URL.Query()
+	opts := ImageOptions{Solution: q.Get("solution") == "true", Progress: q.Get("progress") == "true"}
+	cell := render.DefaultCellSize
+	if raw := q.Get("cell"); raw != "" {
+		cell, err = strconv.Atoi(raw)
+		if err != nil || cell < minImageCell || cell > maxImageCell {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid_cell_size")
+			return
+		}
+	}
 
 	var userID *uint
 	if u := auth.UserFromContext(r.Context()); u != nil {
 		userID = &u.ID
 	}
+	if opts.Progress && userID == nil {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
 
-	resp, err := h.service.Get(r.Context(), id, userID)
+	puzzle, err := h.service.Image(r.Context(), uint(id64), userID, opts)
 	if err != nil {
 		httputil.WriteError(w, httpStatusFromError(err), err.Error())
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, resp)
+	var buf bytes.Buffer
+	draw, contentType := render.SVG, "image/svg+xml"
+	if strings.HasSuffix(r.URL.Path, ".png") {
+		draw, contentType = render.PNG, "image/png"
+	}
+	if err := draw(&buf, puzzle, render.Options{CellSize: cell}); err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "render_failed")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
 }
 
 func (h *handler) complete(w http.ResponseWriter, r *http.Request) {
@@ -345,18 +594,71 @@ func (h *handler) clearProgress(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
-func (h *handler) hintStub(w http.ResponseWriter, _ *http.Request) {
-	httputil.WriteJSON(w, http.StatusOK, HintResponse{
-		Available: false,
-		Reason:    "not_implemented",
-	})
+func (h *handler) hint(w http.ResponseWriter, r *http.Request) {
+	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 0)
+	if err != nil || id64 == 0 {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_id")
+		return
+	}
+
+	var userID *uint
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		userID = &u.ID
+	}
+
+	var req HintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	resp, err := h.service.Hint(r.Context(), uint(id64), userID, req)
+	if err != nil {
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
 }
 
-func (h *handler) optimizeStub(w http.ResponseWriter, _ *http.Request) {
-	httputil.WriteJSON(w, http.StatusOK, OptimizeResponse{
-		Available: false,
-		Reason:    "not_implemented",
-	})
+func (h *handler) optimize(w http.ResponseWriter, r *http.Request) {
+	var req OptimizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	resp, err := h.service.Optimize(r.Context(), req)
+	if err != nil {
+		httputil.WriteError(w, httpStatusFromError(err), err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *handler) generate(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if !errors.Is(err, io.EOF) {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid_json")
+			return
+		}
+	}
+
+	resp, err := h.service.Generate(r.Context(), user.ID, req)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusCreated, resp)
 }
 
 func atoiOrDefault(s string, fallback int) int {
@@ -369,3 +671,18 @@ func atoiOrDefault(s string, fallback int) int {
 	}
 	return v
 }
+
+func parseOptionalFloat(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	// ParseFloat accepts NaN and Inf, which would make the comparisons meaningless.
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, errors.New("invalid_number")
+	}
+	return &v, nil
+}
@@ -1,9 +1,13 @@
 package puzzles
 
 import (
+	"context"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+
+	"sudoku/backend/internal/solver"
 )
 
 // Puzzle represents a Sudoku puzzle.
@@ -11,7 +15,15 @@ type Puzzle struct {
 	ID                         uint      `gorm:"primaryKey" json:"id"`
 	Title                      *string   `gorm:"type:text" json:"title,omitempty"`
 	Givens                     string    `gorm:"not null" json:"givens"`
+	Size                       int       `gorm:"not null;default:9" json:"size"`
+	Cages                      []byte    `gorm:"type:jsonb" json:"-"`
+	Rules                      string    `gorm:"type:text;not null;default:''" json:"rules"`
+	Regions                    string    `gorm:"type:text;not null;default:''" json:"regions"`
+	Geometry                   []byte    `gorm:"type:jsonb" json:"-"`
+	Fingerprint                *string   `gorm:"type:char(64);index" json:"-"`
 	CreatorSuggestedDifficulty int       `gorm:"not null" json:"creatorSuggestedDifficulty"`
+	ComputedDifficulty         *int      `gorm:"index" json:"computedDifficulty,omitempty"`
+	SERating                   *float64  `gorm:"column:se_rating;index" json:"seRating,omitempty"`
 	CreatorUserID              *uint     `gorm:"index" json:"creatorUserId,omitempty"`
 	Published                  bool      `gorm:"not null;default:false" json:"published"`
 	CreatedAt                  time.Time `gorm:"not null" json:"createdAt"`
@@ -35,7 +47,7 @@ type PuzzleProgress struct {
 	ID                 uint      `gorm:"primaryKey" json:"id"`
 	PuzzleID           uint      `gorm:"not null;index;uniqueIndex:idx_progress" json:"puzzleId"`
 	UserID             uint      `gorm:"not null;index;uniqueIndex:idx_progress" json:"userId"`
-	Values             string    `gorm:"type:char(81);not null" json:"values"`
+	Values             string    `gorm:"type:text;not null" json:"values"`
 	CornerNotes        []byte    `gorm:"type:jsonb;not null" json:"cornerNotes"`
 	CenterNotes        []byte    `gorm:"type:jsonb;not null" json:"centerNotes"`
 	FilledCount        int       `gorm:"not null" json:"filledCount"`
@@ -44,11 +56,90 @@ type PuzzleProgress struct {
 	UpdatedAt          time.Time `gorm:"not null" json:"updatedAt"`
 }
 
+// PuzzleHintUsage records the deepest hint level a user has revealed on a puzzle.
+type PuzzleHintUsage struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PuzzleID  uint      `gorm:"not null;index;uniqueIndex:idx_hint_usage" json:"puzzleId"`
+	UserID    uint      `gorm:"not null;index;uniqueIndex:idx_hint_usage" json:"userId"`
+	MaxLevel  int       `gorm:"not null" json:"maxLevel"`
+	CreatedAt time.Time `gorm:"not null" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"not null" json:"updatedAt"`
+}
+
+// backfillFingerprints fingerprints published classic puzzles from before fingerprints
+// were stored, so that Publish can find duplicates among them.
+func backfillFingerprints(db *gorm.DB) error {
+	var puzzles []Puzzle
+	if err := db.Select("id", "givens", "size", "rules", "regions", "cages", "geometry").
+		Where("published = ? AND fingerprint IS NULL", true).
+		Find(&puzzles).Error; err != nil {
+		return err
+	}
+	for i := range puzzles {
+		p := &puzzles[i]
+		// Too few givens for a unique puzzle; the canonical form of a near-empty grid is
+		// also slow to find.
+		if strings.Count(p.Givens, "0")+strings.Count(p.Givens, ".") > 81-17 {
+			continue
+		}
+		fingerprint := p.fingerprint()
+		if fingerprint == nil {
+			continue
+		}
+		if err := db.Model(&Puzzle{}).Where("id = ?", p.ID).Update("fingerprint", *fingerprint).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillRatings rates published classic puzzles that were published before ratings
+// were stored, so that the difficulty and SE filters and sorts include them. Puzzles it
+// cannot rate, or whose solve takes longer than solveTimeout, are left unrated.
+func backfillRatings(db *gorm.DB) error {
+	var puzzles []Puzzle
+	if err := db.Select("id", "givens", "size", "rules", "regions", "cages", "geometry").
+		Where("published = ? AND (computed_difficulty IS NULL OR se_rating IS NULL)", true).
+		Find(&puzzles).Error; err != nil {
+		return err
+	}
+	for i := range puzzles {
+		p := &puzzles[i]
+		if p.gridSize() != 9 || strings.Count(p.Givens, "0")+strings.Count(p.Givens, ".") > 81-17 {
+			continue
+		}
+		if v, err := p.variant(); err != nil || !v.IsClassic() {
+			continue
+		}
+		_, grid, err := solver.ParseGrid(p.Givens)
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), solveTimeout)
+		count, _ := solver.CountSolutionsContext(ctx, grid, 2)
+		cancel()
+		if count != 1 {
+			continue
+		}
+		rating := solver.Rate(grid)
+		if err := db.Model(&Puzzle{}).Where("id = ?", p.ID).Updates(map[string]any{
+			"computed_difficulty": rating.Difficulty,
+			"se_rating":           rating.SERating,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AutoMigrate runs database migrations for puzzle models.
 func AutoMigrate(db *gorm.DB) error {
 	tableExists := db.Migrator().HasTable(&Puzzle{})
 	hadPublished := tableExists && db.Migrator().HasColumn(&Puzzle{}, "published")
 	hadUpdatedAt := tableExists && db.Migrator().HasColumn(&Puzzle{}, "updated_at")
+	hadFingerprint := tableExists && db.Migrator().HasColumn(&Puzzle{}, "fingerprint")
+	hadRatings := tableExists && db.Migrator().HasColumn(&Puzzle{}, "computed_difficulty") &&
+		db.Migrator().HasColumn(&Puzzle{}, "se_rating")
 
 	// Backfill updated_at before AutoMigrate forces NOT NULL.
 	if db.Dialector.Name() == "postgres" && tableExists && !hadUpdatedAt {
@@ -63,7 +154,7 @@ func AutoMigrate(db *gorm.DB) error {
 		}
 	}
 
-	if err := db.AutoMigrate(&Puzzle{}, &PuzzleVote{}, &PuzzleProgress{}); err != nil {
+	if err := db.AutoMigrate(&Puzzle{}, &PuzzleVote{}, &PuzzleProgress{}, &PuzzleHintUsage{}); err != nil {
 		return err
 	}
 
@@ -73,6 +164,37 @@ func AutoMigrate(db *gorm.DB) error {
 		}
 	}
 
+	if tableExists && !hadFingerprint {
+		if err := backfillFingerprints(db); err != nil {
+			return err
+		}
+	}
+
+	// Publish checks for an equivalent published puzzle first; the index catches two
+	// equivalent drafts published at the same time. Duplicates published before
+	// fingerprints existed keep only the oldest fingerprint.
+	if !db.Migrator().HasIndex(&Puzzle{}, "idx_puzzles_published_fingerprint") {
+		if err := db.Exec(`
+			UPDATE puzzles SET fingerprint = NULL
+			WHERE published AND fingerprint IS NOT NULL AND id NOT IN (
+				SELECT MIN(id) FROM puzzles
+				WHERE published AND fingerprint IS NOT NULL
+				GROUP BY fingerprint
+			)
+		`).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_puzzles_published_fingerprint ON puzzles (fingerprint) WHERE published`).Error; err != nil {
+			return err
+		}
+	}
+
+	if tableExists && !hadRatings {
+		if err := backfillRatings(db); err != nil {
+			return err
+		}
+	}
+
 	// The following legacy fixes are Postgres-specific (use of indexes and ALTER COLUMN).
 	votesTableExists := db.Migrator().HasTable(&PuzzleVote{})
 	if db.Dialector.Name() == "postgres" && votesTableExists {
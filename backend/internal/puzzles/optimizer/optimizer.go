@@ -3,12 +3,145 @@ package optimizer
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 
 	"sudoku/backend/internal/solver"
 )
 
-// Optimizer is a stub for a future feature that removes givens in a way that increases
-// difficulty by requiring more advanced human-style techniques (not just backtracking).
+var (
+	// ErrInvalidTarget is returned when the target difficulty is outside 1-10.
+	ErrInvalidTarget = errors.New("invalid_target_difficulty")
+	// ErrNotUnique is returned when the starting grid does not have exactly one solution.
+	ErrNotUnique = solver.ErrNotUnique
+)
+
+// Result is the outcome of an optimization run.
+type Result struct {
+	Grid       solver.Grid
+	Difficulty int
+	SERating   float64
+	Removed    int
+}
+
+// Optimizer removes givens in a way that increases difficulty by requiring more advanced
+// human-style techniques (not just backtracking).
 type Optimizer interface {
-	OptimizeDifficulty(ctx context.Context, grid solver.Grid) (solver.Grid, error)
+	OptimizeDifficulty(ctx context.Context, grid solver.Grid, target int) (Result, error)
+}
+
+// Greedy removes one given at a time. Every removal keeps the solution unique and never
+// rates above the target; the removal that raises the rating most is taken each round.
+type Greedy struct {
+	// Workers rates the candidate removals of a round on that many goroutines. Zero or
+	// one rates them on the calling goroutine.
+	Workers int
+}
+
+// OptimizeDifficulty removes givens until the target difficulty is reached or no more
+// givens can be removed. It returns solver.ErrTimeout once ctx is done.
+func (o Greedy) OptimizeDifficulty(ctx context.Context, grid solver.Grid, target int) (Result, error) {
+	if target < 1 || target > 10 {
+		return Result{}, ErrInvalidTarget
+	}
+	count, err := solver.CountSolutionsContext(ctx, grid, 2)
+	if err != nil {
+		return Result{}, err
+	}
+	if count != 1 {
+		return Result{}, ErrNotUnique
+	}
+
+	current := grid
+	rating := solver.Rate(current)
+	removed := 0
+	for rating.Difficulty < target {
+		next, nextRating, ok, err := nextRemoval(ctx, current, rating, target, o.Workers)
+		if err != nil {
+			return Result{}, err
+		}
+		if !ok {
+			break
+		}
+		current, rating = next, nextRating
+		removed++
+	}
+
+	return Result{
+		Grid:       current,
+		Difficulty: rating.Difficulty,
+		SERating:   rating.SERating,
+		Removed:    removed,
+	}, nil
+}
+
+// nextRemoval evaluates every unique-preserving removal and returns the one with the
+// highest rating (difficulty, then SE) that does not exceed the target.
+func nextRemoval(ctx context.Context, grid solver.Grid, rating solver.Rating, target, workers int) (solver.Grid, solver.Rating, bool, error) {
+	var best solver.Grid
+	var bestRating solver.Rating
+	found := false
+
+	redundant, err := solver.Variant{}.RedundantGivensContext(ctx, grid)
+	if err != nil {
+		return solver.Grid{}, solver.Rating{}, false, err
+	}
+	ratings, err := rateRemovals(ctx, grid, redundant, workers)
+	if err != nil {
+		return solver.Grid{}, solver.Rating{}, false, err
+	}
+	for k, i := range redundant {
+		candidate := grid
+		candidate[i] = 0
+		r := ratings[k]
+		if r.Difficulty > target || r.Difficulty < rating.Difficulty {
+			continue
+		}
+		if !found || r.Difficulty > bestRating.Difficulty ||
+			(r.Difficulty == bestRating.Difficulty && r.SERating > bestRating.SERating) {
+			best, bestRating, found = candidate, r, true
+		}
+	}
+
+	return best, bestRating, found, nil
+}
+
+// rateRemovals rates grid with each of cells removed on its own, spread over workers
+// goroutines.
+func rateRemovals(ctx context.Context, grid solver.Grid, cells []int, workers int) ([]solver.Rating, error) {
+	ratings := make([]solver.Rating, len(cells))
+	if workers <= 1 {
+		for k, i := range cells {
+			if ctx.Err() != nil {
+				return nil, solver.ErrTimeout
+			}
+			candidate := grid
+			candidate[i] = 0
+			ratings[k] = solver.Rate(candidate)
+		}
+		return ratings, nil
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				k := int(next.Add(1) - 1)
+				if k >= len(cells) || ctx.Err() != nil {
+					return
+				}
+				candidate := grid
+				candidate[cells[k]] = 0
+				ratings[k] = solver.Rate(candidate)
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, solver.ErrTimeout
+	}
+	return ratings, nil
 }
@@ -0,0 +1,96 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"sudoku/backend/internal/solver"
+)
+
+const solvedGrid = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+
+func TestGreedyReachesTargetAndKeepsUniqueness(t *testing.T) {
+	t.Parallel()
+
+	_, grid, err := solver.ParseAndNormalize(solvedGrid)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	result, err := Greedy{}.OptimizeDifficulty(context.Background(), grid, 4)
+	if err != nil {
+		t.Fatalf("optimize: %v", err)
+	}
+	if result.Removed == 0 {
+		t.Fatalf("expected givens to be removed")
+	}
+	if result.Difficulty > 4 {
+		t.Fatalf("expected rating not to exceed the target, got %d", result.Difficulty)
+	}
+	if count, _ := solver.CountSolutions(result.Grid, 2); count != 1 {
+		t.Fatalf("expected a unique solution, got %d", count)
+	}
+	if r := solver.Rate(result.Grid); r.Difficulty != result.Difficulty {
+		t.Fatalf("reported rating %d does not match %d", result.Difficulty, r.Difficulty)
+	}
+}
+
+func TestGreedyRejectsNonUniqueGrid(t *testing.T) {
+	t.Parallel()
+
+	var empty solver.Grid
+	if _, err := (Greedy{}).OptimizeDifficulty(context.Background(), empty, 5); err != ErrNotUnique {
+		t.Fatalf("expected ErrNotUnique, got %v", err)
+	}
+}
+
+func TestGreedyStopsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	_, grid, _ := solver.ParseAndNormalize(solvedGrid)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, workers := range []int{0, 4} {
+		if _, err := (Greedy{Workers: workers}).OptimizeDifficulty(ctx, grid, 4); err != solver.ErrTimeout {
+			t.Fatalf("workers %d: expected ErrTimeout, got %v", workers, err)
+		}
+	}
+}
+
+func TestGreedyWithWorkersMatchesSequential(t *testing.T) {
+	t.Parallel()
+
+	_, grid, _ := solver.ParseAndNormalize(solvedGrid)
+	seq, err := Greedy{}.OptimizeDifficulty(context.Background(), grid, 4)
+	if err != nil {
+		t.Fatalf("optimize: %v", err)
+	}
+	par, err := Greedy{Workers: 4}.OptimizeDifficulty(context.Background(), grid, 4)
+	if err != nil {
+		t.Fatalf("optimize with workers: %v", err)
+	}
+	if seq != par {
+		t.Fatalf("expected the same result:\n%+v\n%+v", seq, par)
+	}
+}
+
+// BenchmarkGreedyWorkers optimizes a full grid, rating removals one at a time and on
+// every CPU.
+func BenchmarkGreedyWorkers(b *testing.B) {
+	_, grid, _ := solver.ParseAndNormalize(solvedGrid)
+	counts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		counts = append(counts, n)
+	}
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := (Greedy{Workers: workers}).OptimizeDifficulty(context.Background(), grid, 4); err != nil {
+					b.Fatalf("optimize: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -1,41 +1,65 @@
 package puzzles
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"math"
 	"net/http"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"sudoku/backend/internal/generator"
+	"sudoku/backend/internal/puzzles/optimizer"
 	"sudoku/backend/internal/ranking"
+	"sudoku/backend/internal/render"
 	"sudoku/backend/internal/solver"
 )
 
 var (
 	// ErrNotFound is returned when a puzzle is not found.
 	ErrNotFound = errors.New("not_found")
+
+	// errNotUnique is returned when a solution is asked for and there is none or more
+	// than one.
+	errNotUnique = errors.New("puzzle_not_unique")
 )
 
 // Service provides puzzle management functionality.
 type Service struct {
-	db *gorm.DB
+	db        *gorm.DB
+	optimizer optimizer.Optimizer
 }
 
 // NewService creates a new puzzle service.
 func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+	return &Service{db: db, optimizer: optimizer.Greedy{Workers: runtime.GOMAXPROCS(0)}}
 }
 
 // ValidateRequest contains the request data for puzzle validation.
+// With IncludeSolution set, a uniquely solvable puzzle also gets its solution and the
+// technique engine's solve path. Size defaults to 9; other sizes support classic rules only.
 type ValidateRequest struct {
-	Givens string `json:"givens"`
+	Givens          string          `json:"givens"`
+	Size            int             `json:"size,omitempty"`
+	Rules           []string        `json:"rules,omitempty"`
+	Cages           []solver.Cage   `json:"cages,omitempty"`
+	Regions         string          `json:"regions,omitempty"`
+	Geometry        solver.Geometry `json:"geometry"`
+	IncludeSolution bool            `json:"includeSolution"`
+	// IncludeRedundant asks for the minimality report, which re-solves once per given.
+	IncludeRedundant bool `json:"includeRedundant"`
 }
 
 // ValidateResponse contains the result of puzzle validation.
@@ -46,34 +70,437 @@ type ValidateResponse struct {
 	SolutionCount int      `json:"solutionCount"`
 	Errors        []string `json:"errors,omitempty"`
 	Normalized    string   `json:"normalized,omitempty"`
+
+	// Only set when IncludeSolution was requested and the solution is unique. The solve
+	// path and rating are only computed for classic puzzles.
+	Solution        string        `json:"solution,omitempty"`
+	Steps           []solver.Step `json:"steps,omitempty"`
+	LogicallySolved *bool         `json:"logicallySolved,omitempty"`
+	Difficulty      int           `json:"difficulty,omitempty"`
+	SERating        float64       `json:"seRating,omitempty"`
+
+	// Set when the puzzle has more than one solution.
+	Ambiguity *AmbiguityReport `json:"ambiguity,omitempty"`
+
+	// Only set when IncludeRedundant was requested, the solution is unique and the check
+	// finished within the time limit. RedundantGivens can each be removed on their own
+	// without losing uniqueness; a minimal puzzle has none.
+	Minimal         *bool                 `json:"minimal,omitempty"`
+	RedundantGivens []solver.AffectedCell `json:"redundantGivens,omitempty"`
+}
+
+// AmbiguityReport shows setters why a puzzle is not unique and which given to add.
+type AmbiguityReport struct {
+	Solutions      [2]string             `json:"solutions"`
+	DiffCells      []solver.AffectedCell `json:"diffCells"`
+	SuggestedCell  solver.AffectedCell   `json:"suggestedCell"`
+	SuggestedDigit int                   `json:"suggestedDigit"`
+	// Resolves is true when the suggested given alone makes the solution unique.
+	Resolves bool `json:"resolves"`
+}
+
+// NotUniqueError is returned by Publish when the puzzle does not have exactly one solution.
+// Ambiguity is nil when the puzzle has no solution at all.
+type NotUniqueError struct {
+	Ambiguity *AmbiguityReport
+}
+
+func (e *NotUniqueError) Error() string {
+	return "puzzle_must_have_unique_solution"
+}
+
+// DuplicateError is returned by Publish when an equivalent puzzle, the same up to
+// relabeling, row and column permutations, rotation and reflection, is already published.
+type DuplicateError struct {
+	PuzzleID uint
+}
+
+func (e *DuplicateError) Error() string {
+	return "duplicate_puzzle"
+}
+
+func newAmbiguityReport(a solver.Ambiguity) *AmbiguityReport {
+	r := &AmbiguityReport{
+		Solutions:      [2]string{a.Solutions[0].String(), a.Solutions[1].String()},
+		DiffCells:      make([]solver.AffectedCell, 0, len(a.DiffCells)),
+		SuggestedCell:  cellAt(a.SuggestedCell),
+		SuggestedDigit: int(a.SuggestedDigit),
+		Resolves:       a.Resolves,
+	}
+	for _, idx := range a.DiffCells {
+		r.DiffCells = append(r.DiffCells, cellAt(idx))
+	}
+	return r
+}
+
+// solveTimeout bounds the solver work of a single request, so that a hard or near-empty
+// grid cannot hold a server core. The request's own context can end it sooner.
+const solveTimeout = 5 * time.Second
+
+// Validate validates a puzzle's givens and checks for uniqueness. It returns
+// solver.ErrTimeout when solving takes longer than solveTimeout or ctx is cancelled.
+func (s *Service) Validate(ctx context.Context, req ValidateRequest) (ValidateResponse, error) {
+	size, err := normalizeSize(req.Size)
+	if err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, solveTimeout)
+	defer cancel()
+	if size != 9 {
+		return validateBoard(ctx, req, size)
+	}
+
+	normalized, grid, err := solver.ParseGrid(req.Givens)
+	if err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
+	rules, err := solver.NormalizeRules(req.Rules)
+	if err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
+	regions, err := normalizeRegions(req.Regions)
+	if err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
+	variant := solver.Variant{Rules: rules, Cages: req.Cages, Regions: regions, Geometry: req.Geometry}
+	if err := variant.Validate(grid); err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
+
+	solutions, err := variant.FindSolutionsContext(ctx, grid, 2)
+	if errors.Is(err, solver.ErrTimeout) {
+		return ValidateResponse{}, err
+	}
+	if err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{"solve_failed"}}, nil
+	}
+	count := len(solutions)
+
+	resp := ValidateResponse{
+		Valid:         true,
+		Solvable:      count > 0,
+		Unique:        count == 1,
+		SolutionCount: count,
+		Normalized:    normalized,
+	}
+	if req.IncludeSolution && count == 1 {
+		resp.Solution = solutions[0].String()
+	}
+	if req.IncludeSolution && count == 1 && variant.IsClassic() {
+		rating := solver.Rate(grid)
+		resp.Steps = rating.Steps
+		resp.LogicallySolved = boolPtr(rating.Solved)
+		resp.Difficulty = rating.Difficulty
+		resp.SERating = rating.SERating
+	}
+	if req.IncludeRedundant && count == 1 {
+		// Uniqueness is already known, so running out of time here only drops the report.
+		redundant, err := variant.RedundantGivensContext(ctx, grid)
+		if err == nil {
+			resp.Minimal = boolPtr(len(redundant) == 0)
+			for _, idx := range redundant {
+				resp.RedundantGivens = append(resp.RedundantGivens, cellAt(idx))
+			}
+		}
+	}
+	if count > 1 {
+		a, err := variant.ExplainAmbiguityContext(ctx, grid, solutions[0], solutions[1])
+		if err != nil {
+			return ValidateResponse{}, err
+		}
+		resp.Ambiguity = newAmbiguityReport(a)
+	}
+	return resp, nil
 }
 
-// Validate validates a puzzle's givens and checks for uniqueness.
-func (s *Service) Validate(_ context.Context, req ValidateRequest) (ValidateResponse, error) {
-	normalized, grid, err := solver.ParseAndNormalize(req.Givens)
+// validateBoard validates givens for sizes other than 9x9. The technique engine,
+// ambiguity report and minimality check are 9x9 only, so it reports uniqueness and the
+// solution.
+func validateBoard(ctx context.Context, req ValidateRequest, size int) (ValidateResponse, error) {
+	variant := solver.Variant{Rules: req.Rules, Cages: req.Cages, Regions: strings.TrimSpace(req.Regions), Geometry: req.Geometry}
+	if err := checkVariantSize(size, variant); err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
+	normalized, board, err := solver.ParseBoard(req.Givens, size)
 	if err != nil {
 		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
 	}
+	if err := board.Validate(); err != nil {
+		return ValidateResponse{Valid: false, Errors: []string{err.Error()}}, nil
+	}
 
-	count, err := solver.CountSolutions(grid, 2)
+	solutions, err := solver.FindBoardSolutionsContext(ctx, board, 2)
+	if errors.Is(err, solver.ErrTimeout) {
+		return ValidateResponse{}, err
+	}
 	if err != nil {
 		return ValidateResponse{Valid: false, Errors: []string{"solve_failed"}}, nil
 	}
+	count := len(solutions)
 
-	return ValidateResponse{
+	resp := ValidateResponse{
 		Valid:         true,
 		Solvable:      count > 0,
 		Unique:        count == 1,
 		SolutionCount: count,
 		Normalized:    normalized,
-	}, nil
+	}
+	if req.IncludeSolution && count == 1 {
+		resp.Solution = solutions[0].String()
+	}
+	return resp, nil
+}
+
+// ImportRequest holds puzzles in one of the exchange formats of solver.ParsePuzzles.
+type ImportRequest struct {
+	// Format is a solver.Format; it is detected from the data when empty.
+	Format string `json:"format"`
+	Data   string `json:"data"`
+}
+
+// ImportedPuzzle is one puzzle read by Import, ready to be opened as a draft.
+type ImportedPuzzle struct {
+	Givens string `json:"givens"`
+	Title  string `json:"title,omitempty"`
+	// CenterNotes holds imported candidates as progress notes (bit d-1 for digit d).
+	CenterNotes []int `json:"centerNotes,omitempty"`
+	// Errors lists why the givens cannot be a puzzle, e.g. row_conflict.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportResponse lists the puzzles found in the data, in order.
+type ImportResponse struct {
+	Format  string           `json:"format"`
+	Puzzles []ImportedPuzzle `json:"puzzles"`
+}
+
+// maxImportPuzzles bounds one import; larger collections can be split.
+const maxImportPuzzles = 1000
+
+// Import parses puzzles from an exchange format without storing them. Puzzles whose
+// givens conflict are returned with their errors rather than failing the import.
+func (s *Service) Import(_ context.Context, req ImportRequest) (ImportResponse, error) {
+	format := solver.Format(strings.ToLower(strings.TrimSpace(req.Format)))
+	if format == "" {
+		detected, err := solver.DetectFormat(req.Data)
+		if err != nil {
+			return ImportResponse{}, err
+		}
+		format = detected
+	}
+	parsed, err := solver.ParsePuzzles(req.Data, format)
+	if err != nil {
+		return ImportResponse{}, err
+	}
+	if len(parsed) > maxImportPuzzles {
+		return ImportResponse{}, errors.New("too_many_puzzles")
+	}
+
+	resp := ImportResponse{Format: string(format), Puzzles: make([]ImportedPuzzle, 0, len(parsed))}
+	for _, p := range parsed {
+		out := ImportedPuzzle{Givens: p.Givens.String(), Title: strings.TrimSpace(p.Title)}
+		if p.Candidates != nil {
+			out.CenterNotes = make([]int, 81)
+			for i, m := range p.Candidates {
+				out.CenterNotes[i] = int(m >> 1)
+			}
+		}
+		if err := solver.ValidateNoConflicts(p.Givens); err != nil {
+			out.Errors = []string{err.Error()}
+		}
+		resp.Puzzles = append(resp.Puzzles, out)
+	}
+	return resp, nil
+}
+
+// Bulk import line statuses.
+const (
+	BulkCreated    = "created"
+	BulkParseError = "parse_error"
+	BulkNotUnique  = "not_unique"
+	BulkDuplicate  = "duplicate"
+	BulkFailed     = "failed"
+)
+
+// maxBulkImportLines bounds the puzzle lines of one bulk import.
+const maxBulkImportLines = 5000
+
+// BulkImportResult reports what happened to one line of a bulk import.
+type BulkImportResult struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// PuzzleID is the created draft.
+	PuzzleID uint `json:"puzzleId,omitempty"`
+	// DuplicateOf is the published puzzle, or the earlier line's draft, that the line
+	// repeats.
+	DuplicateOf uint `json:"duplicateOf,omitempty"`
+}
+
+// BulkImport creates a draft for every unique classic puzzle in r, one per line as
+// "<givens> #title". Blank lines and lines starting with '#' are skipped. Each line's
+// result is passed to emit as soon as it is known; an error from emit stops the import.
+// Puzzles equivalent to a published one or an earlier line are reported as duplicates.
+//
+// Lines are parsed, solved and fingerprinted on one goroutine per CPU, a few lines
+// ahead; drafts are created and results emitted in line order. Those goroutines have
+// stopped, and r is no longer read, by the time BulkImport returns.
+func (s *Service) BulkImport(ctx context.Context, userID uint, r io.Reader, emit func(BulkImportResult) error) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		line int
+		text string
+		out  chan checkedLine
+	}
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan job)
+	// pending holds each line's result channel in line order.
+	pending := make(chan chan checkedLine, 2*workers)
+	readErr := make(chan error, 1)
+
+	wg.Add(workers + 1)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.out <- checkImportLine(ctx, j.line, j.text)
+			}
+		}()
+	}
+
+	go func() {
+		defer wg.Done()
+		defer close(pending)
+		defer close(jobs)
+		scanner := bufio.NewScanner(r)
+		puzzles := 0
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			if puzzles++; puzzles > maxBulkImportLines {
+				readErr <- errors.New("too_many_puzzles")
+				return
+			}
+			j := job{line: line, text: text, out: make(chan checkedLine, 1)}
+			select {
+			case pending <- j.out:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			readErr <- errors.New("invalid_file")
+			return
+		}
+		readErr <- nil
+	}()
+
+	seen := make(map[string]uint)
+	for out := range pending {
+		checked := <-out
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := emit(s.storeImportLine(ctx, userID, checked, seen)); err != nil {
+			return err
+		}
+	}
+	return <-readErr
+}
+
+// checkedLine is a bulk import line after the checks that need no database.
+type checkedLine struct {
+	// result is final when its Status is set.
+	result      BulkImportResult
+	normalized  string
+	title       string
+	fingerprint string
+}
+
+// checkImportLine parses one bulk import line, checks that its solution is unique and
+// fingerprints it.
+func checkImportLine(ctx context.Context, line int, text string) checkedLine {
+	c := checkedLine{result: BulkImportResult{Line: line}}
+	givens, title, _ := strings.Cut(text, "#")
+	normalized, grid, err := solver.ParseAndNormalize(givens)
+	if err != nil {
+		c.result.Status, c.result.Error = BulkParseError, err.Error()
+		return c
+	}
+
+	solveCtx, cancel := context.WithTimeout(ctx, solveTimeout)
+	count, err := solver.CountSolutionsContext(solveCtx, grid, 2)
+	cancel()
+	if err != nil {
+		c.result.Status, c.result.Error = BulkFailed, err.Error()
+		return c
+	}
+	if count != 1 {
+		c.result.Status, c.result.Error = BulkNotUnique, (&NotUniqueError{}).Error()
+		return c
+	}
+
+	c.normalized, c.title, c.fingerprint = normalized, strings.TrimSpace(title), solver.Fingerprint(grid)
+	return c
+}
+
+// storeImportLine creates the draft of a checked line unless it repeats a published
+// puzzle or an earlier line.
+func (s *Service) storeImportLine(ctx context.Context, userID uint, c checkedLine, seen map[string]uint) BulkImportResult {
+	result := c.result
+	if result.Status != "" {
+		return result
+	}
+	if id, ok := seen[c.fingerprint]; ok {
+		result.Status, result.DuplicateOf = BulkDuplicate, id
+		return result
+	}
+	var duplicate *DuplicateError
+	if err := s.checkDuplicate(ctx, c.fingerprint, 0); errors.As(err, &duplicate) {
+		result.Status, result.DuplicateOf = BulkDuplicate, duplicate.PuzzleID
+		return result
+	} else if err != nil {
+		result.Status, result.Error = BulkFailed, err.Error()
+		return result
+	}
+
+	req := CreatePuzzleRequest{Givens: c.normalized}
+	if c.title != "" {
+		req.Title = &c.title
+	}
+	created, err := s.Create(ctx, userID, req)
+	if err != nil {
+		result.Status, result.Error = BulkFailed, err.Error()
+		return result
+	}
+	seen[c.fingerprint] = created.ID
+	result.Status, result.PuzzleID = BulkCreated, created.ID
+	return result
 }
 
 // CreatePuzzleRequest contains the data needed to create a puzzle.
 type CreatePuzzleRequest struct {
-	Title                      *string `json:"title"`
-	Givens                     string  `json:"givens"`
-	CreatorSuggestedDifficulty int     `json:"creatorSuggestedDifficulty"`
+	Title                      *string         `json:"title"`
+	Givens                     string          `json:"givens"`
+	Size                       int             `json:"size,omitempty"`
+	Rules                      []string        `json:"rules,omitempty"`
+	Cages                      []solver.Cage   `json:"cages,omitempty"`
+	Regions                    string          `json:"regions,omitempty"`
+	Geometry                   solver.Geometry `json:"geometry"`
+	CreatorSuggestedDifficulty int             `json:"creatorSuggestedDifficulty"`
 }
 
 // CreatePuzzleResponse contains the ID of the created puzzle.
@@ -88,13 +515,43 @@ func (s *Service) Create(ctx context.Context, creatorUserID uint, req CreatePuzz
 		difficulty = 1
 	}
 
-	normalized := normalizeDraftGivens(req.Givens)
+	size, err := normalizeSize(req.Size)
+	if err != nil {
+		return CreatePuzzleResponse{}, err
+	}
+	normalized := normalizeDraftGivens(req.Givens, size)
 
 	title := normalizeTitle(req.Title)
 
+	cages, err := encodeCages(req.Cages)
+	if err != nil {
+		return CreatePuzzleResponse{}, err
+	}
+	rules, err := solver.NormalizeRules(req.Rules)
+	if err != nil {
+		return CreatePuzzleResponse{}, err
+	}
+	regions, err := normalizeRegions(req.Regions)
+	if err != nil {
+		return CreatePuzzleResponse{}, err
+	}
+	geometry, err := encodeGeometry(req.Geometry)
+	if err != nil {
+		return CreatePuzzleResponse{}, err
+	}
+	variant := solver.Variant{Rules: rules, Cages: req.Cages, Regions: regions, Geometry: req.Geometry}
+	if err := checkVariantSize(size, variant); err != nil {
+		return CreatePuzzleResponse{}, err
+	}
+
 	p := Puzzle{
 		Title:                      title,
 		Givens:                     normalized,
+		Size:                       size,
+		Cages:                      cages,
+		Rules:                      strings.Join(rules, ","),
+		Regions:                    regions,
+		Geometry:                   geometry,
 		CreatorSuggestedDifficulty: difficulty,
 		CreatorUserID:              &creatorUserID,
 		Published:                  false,
@@ -108,10 +565,16 @@ func (s *Service) Create(ctx context.Context, creatorUserID uint, req CreatePuzz
 }
 
 // UpdatePuzzleRequest contains the data needed to update a puzzle.
+// A zero Size keeps the draft's current size.
 type UpdatePuzzleRequest struct {
-	Title                      *string `json:"title"`
-	Givens                     string  `json:"givens"`
-	CreatorSuggestedDifficulty int     `json:"creatorSuggestedDifficulty"`
+	Title                      *string         `json:"title"`
+	Givens                     string          `json:"givens"`
+	Size                       int             `json:"size,omitempty"`
+	Rules                      []string        `json:"rules,omitempty"`
+	Cages                      []solver.Cage   `json:"cages,omitempty"`
+	Regions                    string          `json:"regions,omitempty"`
+	Geometry                   solver.Geometry `json:"geometry"`
+	CreatorSuggestedDifficulty int             `json:"creatorSuggestedDifficulty"`
 }
 
 // Update updates an existing puzzle draft.
@@ -135,8 +598,40 @@ func (s *Service) Update(ctx context.Context, puzzleID uint, userID uint, req Up
 		return PuzzleDetail{}, errors.New("already_published")
 	}
 
+	cages, err := encodeCages(req.Cages)
+	if err != nil {
+		return PuzzleDetail{}, err
+	}
+	rules, err := solver.NormalizeRules(req.Rules)
+	if err != nil {
+		return PuzzleDetail{}, err
+	}
+	regions, err := normalizeRegions(req.Regions)
+	if err != nil {
+		return PuzzleDetail{}, err
+	}
+	geometry, err := encodeGeometry(req.Geometry)
+	if err != nil {
+		return PuzzleDetail{}, err
+	}
+	size := puzzle.gridSize()
+	if req.Size != 0 {
+		if size, err = normalizeSize(req.Size); err != nil {
+			return PuzzleDetail{}, err
+		}
+	}
+	variant := solver.Variant{Rules: rules, Cages: req.Cages, Regions: regions, Geometry: req.Geometry}
+	if err := checkVariantSize(size, variant); err != nil {
+		return PuzzleDetail{}, err
+	}
+
 	puzzle.Title = normalizeTitle(req.Title)
-	puzzle.Givens = normalizeDraftGivens(req.Givens)
+	puzzle.Givens = normalizeDraftGivens(req.Givens, size)
+	puzzle.Size = size
+	puzzle.Cages = cages
+	puzzle.Rules = strings.Join(rules, ",")
+	puzzle.Regions = regions
+	puzzle.Geometry = geometry
 	puzzle.CreatorSuggestedDifficulty = difficulty
 
 	if err := s.db.WithContext(ctx).Save(&puzzle).Error; err != nil {
@@ -167,28 +662,106 @@ func (s *Service) Publish(ctx context.Context, puzzleID uint, userID uint) (Puzz
 		_ = s.db.WithContext(ctx).Model(&puzzle).Update("creator_suggested_difficulty", 1)
 	}
 
-	normalized, grid, err := solver.ParseAndNormalize(puzzle.Givens)
-	if err != nil {
-		return PuzzleDetail{}, errors.New("invalid_givens")
+	if err := preparePublish(ctx, &puzzle); err != nil {
+		return PuzzleDetail{}, err
 	}
-	count, err := solver.CountSolutions(grid, 2)
-	if err != nil {
-		return PuzzleDetail{}, errors.New("solve_failed")
-	}
-	if count != 1 {
-		return PuzzleDetail{}, errors.New("puzzle_must_have_unique_solution")
+	if puzzle.Fingerprint != nil {
+		if err := s.checkDuplicate(ctx, *puzzle.Fingerprint, puzzle.ID); err != nil {
+			return PuzzleDetail{}, err
+		}
 	}
-
-	puzzle.Givens = normalized
 	puzzle.Published = true
 
 	if err := s.db.WithContext(ctx).Save(&puzzle).Error; err != nil {
+		// An equivalent puzzle published since the check trips the unique fingerprint index.
+		var duplicate *DuplicateError
+		if puzzle.Fingerprint != nil && errors.As(s.checkDuplicate(ctx, *puzzle.Fingerprint, puzzle.ID), &duplicate) {
+			return PuzzleDetail{}, duplicate
+		}
 		return PuzzleDetail{}, errors.New("db_update_failed")
 	}
 
 	return s.Get(ctx, puzzleID, &userID)
 }
 
+// checkDuplicate returns a DuplicateError when a published puzzle other than excludeID
+// has the fingerprint.
+func (s *Service) checkDuplicate(ctx context.Context, fingerprint string, excludeID uint) error {
+	var existing Puzzle
+	err := s.db.WithContext(ctx).Select("id").
+		Where("fingerprint = ? AND published = ? AND id <> ?", fingerprint, true, excludeID).
+		First(&existing).Error
+	if err == nil {
+		return &DuplicateError{PuzzleID: existing.ID}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("db_query_failed")
+	}
+	return nil
+}
+
+// preparePublish checks that the puzzle has exactly one solution, then normalizes its
+// givens, rates it when the technique engine supports its rules and fingerprints it.
+func preparePublish(ctx context.Context, p *Puzzle) error {
+	ctx, cancel := context.WithTimeout(ctx, solveTimeout)
+	defer cancel()
+	if size := p.gridSize(); size != 9 {
+		normalized, board, err := solver.ParseBoard(p.Givens, size)
+		if err != nil || board.Validate() != nil {
+			return errors.New("invalid_givens")
+		}
+		count, err := solver.CountBoardSolutionsContext(ctx, board, 2)
+		if errors.Is(err, solver.ErrTimeout) {
+			return err
+		}
+		if err != nil {
+			return errors.New("solve_failed")
+		}
+		if count != 1 {
+			return &NotUniqueError{}
+		}
+		p.Givens = normalized
+		return nil
+	}
+
+	normalized, grid, err := solver.ParseGrid(p.Givens)
+	if err != nil {
+		return errors.New("invalid_givens")
+	}
+	variant, err := p.variant()
+	if err != nil {
+		return err
+	}
+	if err := variant.Validate(grid); err != nil {
+		return errors.New("invalid_givens")
+	}
+	solutions, err := variant.FindSolutionsContext(ctx, grid, 2)
+	if errors.Is(err, solver.ErrTimeout) {
+		return err
+	}
+	if err != nil {
+		return errors.New("solve_failed")
+	}
+	if len(solutions) != 1 {
+		notUnique := &NotUniqueError{}
+		if len(solutions) > 1 {
+			if a, err := variant.ExplainAmbiguityContext(ctx, grid, solutions[0], solutions[1]); err == nil {
+				notUnique.Ambiguity = newAmbiguityReport(a)
+			}
+		}
+		return notUnique
+	}
+
+	p.Givens = normalized
+	if variant.IsClassic() {
+		rating := solver.Rate(grid)
+		p.ComputedDifficulty = &rating.Difficulty
+		p.SERating = &rating.SERating
+	}
+	p.Fingerprint = p.fingerprint()
+	return nil
+}
+
 // Delete deletes a puzzle draft.
 func (s *Service) Delete(ctx context.Context, puzzleID uint, userID uint) error {
 	var puzzle Puzzle
@@ -214,6 +787,10 @@ func (s *Service) Delete(ctx context.Context, puzzleID uint, userID uint) error
 		tx.Rollback()
 		return errors.New("db_delete_failed")
 	}
+	if err := tx.Where("puzzle_id = ?", puzzleID).Delete(&PuzzleHintUsage{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("db_delete_failed")
+	}
 	if err := tx.Delete(&Puzzle{ID: puzzleID}).Error; err != nil {
 		tx.Rollback()
 		return errors.New("db_delete_failed")
@@ -226,12 +803,15 @@ func (s *Service) Delete(ctx context.Context, puzzleID uint, userID uint) error
 }
 
 // ListRequest contains parameters for listing puzzles.
+// MinSERating and MaxSERating filter on the SE rating (inclusive); unrated puzzles are excluded.
 type ListRequest struct {
-	Difficulty *int
-	Sort       string
-	Page       int
-	PageSize   int
-	UserID     *uint
+	Difficulty  *int
+	MinSERating *float64
+	MaxSERating *float64
+	Sort        string
+	Page        int
+	PageSize    int
+	UserID      *uint
 }
 
 // ProgressSummary represents puzzle completion progress.
@@ -246,7 +826,10 @@ type PuzzleSummary struct {
 	ID                         uint             `json:"id"`
 	Title                      *string          `json:"title,omitempty"`
 	Givens                     string           `json:"givens"`
+	Size                       int              `json:"size"`
 	CreatorSuggestedDifficulty int              `json:"creatorSuggestedDifficulty"`
+	ComputedDifficulty         *int             `json:"computedDifficulty,omitempty"`
+	SERating                   *float64         `json:"seRating,omitempty"`
 	AggregatedDifficulty       int              `json:"aggregatedDifficulty"`
 	Published                  bool             `json:"published"`
 	Likes                      int              `json:"likes"`
@@ -270,7 +853,14 @@ type puzzleStatsRow struct {
 	ID                         uint
 	Title                      *string
 	Givens                     string
+	Size                       int
+	Rules                      string
+	Regions                    string
+	Cages                      []byte
+	Geometry                   []byte
 	CreatorSuggestedDifficulty int
+	ComputedDifficulty         *int
+	SERating                   *float64 `gorm:"column:se_rating"`
 	Published                  bool
 	CreatorUserID              *uint
 	CreatedAt                  time.Time
@@ -296,7 +886,10 @@ func (s *Service) List(ctx context.Context, req ListRequest) (ListResponse, erro
 			p.id as id,
 			p.title as title,
 			p.givens as givens,
+			p.size as size,
 			p.creator_suggested_difficulty as creator_suggested_difficulty,
+			p.computed_difficulty as computed_difficulty,
+			p.se_rating as se_rating,
 			p.published as published,
 			p.created_at as created_at,
 			COUNT(v.id) as vote_count,
@@ -314,23 +907,26 @@ func (s *Service) List(ctx context.Context, req ListRequest) (ListResponse, erro
 
 	items := make([]PuzzleSummary, 0, len(rows))
 	for _, row := range rows {
-		agg := row.CreatorSuggestedDifficulty
-		if row.DifficultyAvg != nil && !math.IsNaN(*row.DifficultyAvg) {
-			agg = int(math.Round(*row.DifficultyAvg))
-			if agg <= 0 {
-				agg = row.CreatorSuggestedDifficulty
-			}
-		}
+		agg := aggregatedDifficulty(row)
 
 		if req.Difficulty != nil && agg != *req.Difficulty {
 			continue
 		}
+		if req.MinSERating != nil && (row.SERating == nil || *row.SERating < *req.MinSERating) {
+			continue
+		}
+		if req.MaxSERating != nil && (row.SERating == nil || *row.SERating > *req.MaxSERating) {
+			continue
+		}
 
 		items = append(items, PuzzleSummary{
 			ID:                         row.ID,
 			Title:                      row.Title,
 			Givens:                     row.Givens,
+			Size:                       row.Size,
 			CreatorSuggestedDifficulty: row.CreatorSuggestedDifficulty,
+			ComputedDifficulty:         row.ComputedDifficulty,
+			SERating:                   row.SERating,
 			AggregatedDifficulty:       agg,
 			Published:                  row.Published,
 			Likes:                      row.Likes,
@@ -354,6 +950,22 @@ func (s *Service) List(ctx context.Context, req ListRequest) (ListResponse, erro
 		sort.Slice(items, func(i, j int) bool {
 			return items[i].CreatedAt.After(items[j].CreatedAt)
 		})
+	case "se_asc", "se_desc":
+		desc := sortMode == "se_desc"
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := items[i].SERating, items[j].SERating
+			switch {
+			case a == nil || b == nil:
+				// Unrated puzzles always go last.
+				return a != nil && b == nil
+			case *a == *b:
+				return items[i].CreatedAt.After(items[j].CreatedAt)
+			case desc:
+				return *a > *b
+			default:
+				return *a < *b
+			}
+		})
 	}
 
 	total := len(items)
@@ -433,17 +1045,24 @@ func (s *Service) List(ctx context.Context, req ListRequest) (ListResponse, erro
 
 // PuzzleDetail contains detailed information about a puzzle.
 type PuzzleDetail struct {
-	ID                         uint      `json:"id"`
-	Title                      *string   `json:"title,omitempty"`
-	Givens                     string    `json:"givens"`
-	CreatorSuggestedDifficulty int       `json:"creatorSuggestedDifficulty"`
-	AggregatedDifficulty       int       `json:"aggregatedDifficulty"`
-	Published                  bool      `json:"published"`
-	Likes                      int       `json:"likes"`
-	Dislikes                   int       `json:"dislikes"`
-	CompletionCount            int       `json:"completionCount"`
-	GoodnessRank               float64   `json:"goodnessRank"`
-	CreatedAt                  time.Time `json:"createdAt"`
+	ID                         uint             `json:"id"`
+	Title                      *string          `json:"title,omitempty"`
+	Givens                     string           `json:"givens"`
+	Size                       int              `json:"size"`
+	Rules                      []string         `json:"rules,omitempty"`
+	Cages                      []solver.Cage    `json:"cages,omitempty"`
+	Regions                    string           `json:"regions,omitempty"`
+	Geometry                   *solver.Geometry `json:"geometry,omitempty"`
+	CreatorSuggestedDifficulty int              `json:"creatorSuggestedDifficulty"`
+	ComputedDifficulty         *int             `json:"computedDifficulty,omitempty"`
+	SERating                   *float64         `json:"seRating,omitempty"`
+	AggregatedDifficulty       int              `json:"aggregatedDifficulty"`
+	Published                  bool             `json:"published"`
+	Likes                      int              `json:"likes"`
+	Dislikes                   int              `json:"dislikes"`
+	CompletionCount            int              `json:"completionCount"`
+	GoodnessRank               float64          `json:"goodnessRank"`
+	CreatedAt                  time.Time        `json:"createdAt"`
 }
 
 // Get retrieves a puzzle by ID.
@@ -455,7 +1074,14 @@ func (s *Service) Get(ctx context.Context, id uint, userID *uint) (PuzzleDetail,
 			p.id as id,
 			p.title as title,
 			p.givens as givens,
+			p.size as size,
+			p.rules as rules,
+			p.regions as regions,
+			p.cages as cages,
+			p.geometry as geometry,
 			p.creator_suggested_difficulty as creator_suggested_difficulty,
+			p.computed_difficulty as computed_difficulty,
+			p.se_rating as se_rating,
 			p.creator_user_id as creator_user_id,
 			p.published as published,
 			p.created_at as created_at,
@@ -485,19 +1111,32 @@ func (s *Service) Get(ctx context.Context, id uint, userID *uint) (PuzzleDetail,
 		}
 	}
 
-	agg := row.CreatorSuggestedDifficulty
-	if row.DifficultyAvg != nil && !math.IsNaN(*row.DifficultyAvg) {
-		agg = int(math.Round(*row.DifficultyAvg))
-		if agg <= 0 {
-			agg = row.CreatorSuggestedDifficulty
+	var cages []solver.Cage
+	if len(row.Cages) > 0 {
+		_ = json.Unmarshal(row.Cages, &cages)
+	}
+	var geometry *solver.Geometry
+	if len(row.Geometry) > 0 {
+		geometry = &solver.Geometry{}
+		if err := json.Unmarshal(row.Geometry, geometry); err != nil {
+			geometry = nil
 		}
 	}
 
+	agg := aggregatedDifficulty(row)
+
 	return PuzzleDetail{
 		ID:                         row.ID,
 		Title:                      row.Title,
 		Givens:                     row.Givens,
+		Size:                       row.Size,
+		Rules:                      splitRules(row.Rules),
+		Cages:                      cages,
+		Regions:                    row.Regions,
+		Geometry:                   geometry,
 		CreatorSuggestedDifficulty: row.CreatorSuggestedDifficulty,
+		ComputedDifficulty:         row.ComputedDifficulty,
+		SERating:                   row.SERating,
 		AggregatedDifficulty:       agg,
 		Published:                  row.Published,
 		Likes:                      row.Likes,
@@ -508,6 +1147,315 @@ func (s *Service) Get(ctx context.Context, id uint, userID *uint) (PuzzleDetail,
 	}, nil
 }
 
+// ExportFormatJSON is the JSON bundle export format; the others are solver formats.
+const ExportFormatJSON = "json"
+
+// maxExportPuzzles bounds one collection export.
+const maxExportPuzzles = 500
+
+// exportFormats maps each export format to its file extension and content type.
+var exportFormats = map[string][2]string{
+	string(solver.FormatFlat):         {"txt", "text/plain; charset=utf-8"},
+	string(solver.FormatSDK):          {"sdk", "text/plain; charset=utf-8"},
+	string(solver.FormatSimpleSudoku): {"ss", "text/plain; charset=utf-8"},
+	string(solver.FormatOpenSudoku):   {"opensudoku", "application/xml; charset=utf-8"},
+	ExportFormatJSON:                  {"json", "application/json; charset=utf-8"},
+}
+
+// ExportBundle is the JSON export of a puzzle or collection.
+type ExportBundle struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exportedAt"`
+	Puzzles    []ExportedPuzzle `json:"puzzles"`
+}
+
+// ExportedPuzzle is a puzzle's detail, stats included, with its creator.
+type ExportedPuzzle struct {
+	PuzzleDetail
+	Creator *ExportCreator `json:"creator,omitempty"`
+}
+
+// ExportCreator is the public part of a puzzle creator's account.
+type ExportCreator struct {
+	ID          uint    `json:"id"`
+	DisplayName *string `json:"displayName,omitempty"`
+}
+
+// ExportFile is an export ready to download.
+type ExportFile struct {
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+// Export writes the puzzles, in order, as flat lines, .sdk, .ss, OpenSudoku XML or a
+// JSON bundle. The text formats hold classic 9x9 puzzles only. Every puzzle must be
+// visible to userID, as for Get.
+func (s *Service) Export(ctx context.Context, ids []uint, userID *uint, format string) (ExportFile, error) {
+	if format == "" {
+		format = string(solver.FormatFlat)
+	}
+	info, ok := exportFormats[format]
+	if !ok {
+		return ExportFile{}, errors.New("unknown_format")
+	}
+	if len(ids) == 0 {
+		return ExportFile{}, errors.New("invalid_ids")
+	}
+	if len(ids) > maxExportPuzzles {
+		return ExportFile{}, errors.New("too_many_puzzles")
+	}
+
+	details := make([]PuzzleDetail, 0, len(ids))
+	for _, id := range ids {
+		detail, err := s.Get(ctx, id, userID)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		details = append(details, detail)
+	}
+
+	file := ExportFile{ContentType: info[1], Filename: "puzzles." + info[0]}
+	if len(ids) == 1 {
+		file.Filename = fmt.Sprintf("puzzle-%d.%s", ids[0], info[0])
+	}
+
+	if format == ExportFormatJSON {
+		creators, err := s.exportCreators(ctx, ids)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		bundle := ExportBundle{Version: 1, ExportedAt: time.Now().UTC(), Puzzles: make([]ExportedPuzzle, 0, len(details))}
+		for _, d := range details {
+			bundle.Puzzles = append(bundle.Puzzles, ExportedPuzzle{PuzzleDetail: d, Creator: creators[d.ID]})
+		}
+		body, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return ExportFile{}, errors.New("export_failed")
+		}
+		file.Body = append(body, '\n')
+		return file, nil
+	}
+
+	entries := make([]solver.PuzzleEntry, 0, len(details))
+	for _, d := range details {
+		if d.Size != 9 || len(d.Rules) > 0 || len(d.Cages) > 0 || d.Regions != "" || d.Geometry != nil {
+			return ExportFile{}, errors.New("format_requires_classic")
+		}
+		_, grid, err := solver.ParseGrid(d.Givens)
+		if err != nil {
+			return ExportFile{}, errors.New("invalid_givens")
+		}
+		entry := solver.PuzzleEntry{Givens: grid}
+		if d.Title != nil {
+			entry.Title = *d.Title
+		}
+		entries = append(entries, entry)
+	}
+	body, err := solver.WritePuzzles(entries, solver.Format(format))
+	if err != nil {
+		return ExportFile{}, err
+	}
+	file.Body = []byte(body)
+	return file, nil
+}
+
+// exportCreators returns the creator of each puzzle that has one, keyed by puzzle ID.
+func (s *Service) exportCreators(ctx context.Context, ids []uint) (map[uint]*ExportCreator, error) {
+	var rows []struct {
+		PuzzleID    uint
+		UserID      uint
+		DisplayName *string
+	}
+	if err := s.db.WithContext(ctx).
+		Table("puzzles p").
+		Select("p.id as puzzle_id, u.id as user_id, u.display_name as display_name").
+		Joins("JOIN users u ON u.id = p.creator_user_id").
+		Where("p.id IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return nil, errors.New("db_query_failed")
+	}
+	creators := make(map[uint]*ExportCreator, len(rows))
+	for _, row := range rows {
+		creators[row.PuzzleID] = &ExportCreator{ID: row.UserID, DisplayName: row.DisplayName}
+	}
+	return creators, nil
+}
+
+// ImageOptions selects what Image draws besides the givens.
+type ImageOptions struct {
+	// Solution fills the empty cells with the solution; the puzzle must be unique.
+	Solution bool
+	// Progress draws the user's saved values and notes; it needs a user.
+	Progress bool
+}
+
+// Image returns the puzzle as a render.Puzzle, visible to the same users as Get.
+func (s *Service) Image(ctx context.Context, id uint, userID *uint, opts ImageOptions) (render.Puzzle, error) {
+	var puzzle Puzzle
+	if err := s.db.WithContext(ctx).Select("id", "givens", "size", "rules", "regions", "cages", "geometry", "creator_user_id", "published").First(&puzzle, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return render.Puzzle{}, ErrNotFound
+		}
+		return render.Puzzle{}, errors.New("db_query_failed")
+	}
+	if !puzzle.Published && (puzzle.CreatorUserID == nil || userID == nil || *puzzle.CreatorUserID != *userID) {
+		return render.Puzzle{}, ErrNotFound
+	}
+
+	size := puzzle.gridSize()
+	_, board, err := solver.ParseBoard(puzzle.Givens, size)
+	if err != nil {
+		return render.Puzzle{}, errors.New("invalid_givens")
+	}
+	out := render.Puzzle{Size: size, Givens: board.Cells, Regions: puzzle.Regions}
+
+	if opts.Progress {
+		if userID == nil {
+			return render.Puzzle{}, errors.New("progress_requires_user")
+		}
+		var pr PuzzleProgress
+		err := s.db.WithContext(ctx).Where("puzzle_id = ? AND user_id = ?", id, *userID).First(&pr).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+		case err != nil:
+			return render.Puzzle{}, errors.New("db_query_failed")
+		default:
+			if _, values, err := solver.ParseBoard(pr.Values, size); err == nil {
+				out.Values = values.Cells
+			}
+			var corner, center []int
+			_ = json.Unmarshal(pr.CornerNotes, &corner)
+			_ = json.Unmarshal(pr.CenterNotes, &center)
+			out.Notes = make([]uint16, size*size)
+			for i := range out.Notes {
+				if i < len(corner) {
+					out.Notes[i] |= uint16(corner[i])
+				}
+				if i < len(center) {
+					out.Notes[i] |= uint16(center[i])
+				}
+			}
+		}
+	}
+
+	if opts.Solution {
+		solution, err := puzzle.solve(ctx, board)
+		if err != nil {
+			return render.Puzzle{}, err
+		}
+		out.Solution = solution
+	}
+	return out, nil
+}
+
+// solve returns the unique solution of the puzzle's givens, parsed as board.
+func (p *Puzzle) solve(ctx context.Context, board solver.Board) ([]uint8, error) {
+	solveCtx, cancel := context.WithTimeout(ctx, solveTimeout)
+	defer cancel()
+
+	if board.Size != 9 {
+		solutions, err := solver.FindBoardSolutionsContext(solveCtx, board, 2)
+		if err != nil {
+			return nil, err
+		}
+		if len(solutions) != 1 {
+			return nil, errNotUnique
+		}
+		return solutions[0].Cells, nil
+	}
+
+	variant, err := p.variant()
+	if err != nil {
+		return nil, err
+	}
+	var grid solver.Grid
+	copy(grid[:], board.Cells)
+	solutions, err := variant.FindSolutionsContext(solveCtx, grid, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(solutions) != 1 {
+		return nil, errNotUnique
+	}
+	return solutions[0][:], nil
+}
+
+// maxBookletPuzzles bounds the puzzles of one PDF booklet.
+const maxBookletPuzzles = 100
+
+// BookletRequest selects and lays out the puzzles of a PDF booklet.
+type BookletRequest struct {
+	// IDs lists the puzzles in order. When empty, the puzzles are one page of List
+	// with Filter.
+	IDs    []uint
+	Filter ListRequest
+	// Title, PerPage and Paper are passed to render.PDF.
+	Title   string
+	PerPage int
+	Paper   string
+}
+
+// Booklet renders the requested puzzles, visible to the same users as Get, as a PDF
+// with their titles and difficulty, and an answer key of those with a unique solution.
+// All the answers share one solveTimeout; puzzles not solved by then have no answer.
+func (s *Service) Booklet(ctx context.Context, userID *uint, req BookletRequest) (ExportFile, error) {
+	ids := req.IDs
+	if len(ids) == 0 {
+		filter := req.Filter
+		filter.UserID = userID
+		list, err := s.List(ctx, filter)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		for _, item := range list.Items {
+			ids = append(ids, item.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return ExportFile{}, errors.New("no_puzzles")
+	}
+	if len(ids) > maxBookletPuzzles {
+		return ExportFile{}, errors.New("too_many_puzzles")
+	}
+
+	solveCtx, cancel := context.WithTimeout(ctx, solveTimeout)
+	defer cancel()
+	puzzles := make([]render.BookletPuzzle, 0, len(ids))
+	for _, id := range ids {
+		detail, err := s.Get(ctx, id, userID)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		var img render.Puzzle
+		err = solver.ErrTimeout
+		if solveCtx.Err() == nil {
+			img, err = s.Image(solveCtx, id, userID, ImageOptions{Solution: true})
+		}
+		if errors.Is(err, errNotUnique) || errors.Is(err, solver.ErrTimeout) || (err != nil && solveCtx.Err() != nil) {
+			img, err = s.Image(ctx, id, userID, ImageOptions{})
+		}
+		if err != nil {
+			return ExportFile{}, err
+		}
+
+		entry := render.BookletPuzzle{Puzzle: img, Caption: fmt.Sprintf("Difficulty %d", detail.AggregatedDifficulty)}
+		if detail.Title != nil {
+			entry.Title = *detail.Title
+		}
+		if detail.SERating != nil {
+			entry.Caption += fmt.Sprintf(" · SE %.1f", *detail.SERating)
+		}
+		puzzles = append(puzzles, entry)
+	}
+
+	var body bytes.Buffer
+	if err := render.PDF(&body, puzzles, render.BookletOptions{Title: strings.TrimSpace(req.Title), PerPage: req.PerPage, Paper: req.Paper}); err != nil {
+		return ExportFile{}, err
+	}
+	return ExportFile{ContentType: "application/pdf", Filename: "booklet.pdf", Body: body.Bytes()}, nil
+}
+
 // CompleteRequest contains the data for completing a puzzle.
 type CompleteRequest struct {
 	TimeMs         int   `json:"timeMs"`
@@ -583,22 +1531,319 @@ func (s *Service) Complete(ctx context.Context, puzzleID uint, userID *uint, pla
 	return CompleteResponse{OK: true}, nil
 }
 
+// HintRequest contains the player's current board state, in the same shape as SaveProgressRequest.
+// Level (1-4) selects how much of the hint to reveal; it defaults to a level 1 nudge.
+type HintRequest struct {
+	Values      string `json:"values"`
+	CornerNotes []int  `json:"cornerNotes"`
+	CenterNotes []int  `json:"centerNotes"`
+	Level       int    `json:"level"`
+}
+
 // HintResponse contains the response for hint requests.
+// When the board contains a mistake, Reason explains it and MistakeCells points at it.
+// MaxLevelReached is the deepest level the signed-in user has revealed on this puzzle.
 type HintResponse struct {
-	Available bool   `json:"available"`
-	Reason    string `json:"reason,omitempty"`
+	Available       bool                  `json:"available"`
+	Reason          string                `json:"reason,omitempty"`
+	Hint            *solver.Hint          `json:"hint,omitempty"`
+	MistakeCells    []solver.AffectedCell `json:"mistakeCells,omitempty"`
+	MaxLevelReached int                   `json:"maxLevelReached,omitempty"`
+}
+
+// Hint returns the next logical step for the player's current values and pencil marks.
+func (s *Service) Hint(ctx context.Context, puzzleID uint, userID *uint, req HintRequest) (HintResponse, error) {
+	var puzzle Puzzle
+	if err := s.db.WithContext(ctx).Select("id", "givens", "size", "rules", "regions", "cages", "geometry", "creator_user_id", "published").First(&puzzle, puzzleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return HintResponse{}, ErrNotFound
+		}
+		return HintResponse{}, errors.New("db_query_failed")
+	}
+	if !puzzle.Published && (puzzle.CreatorUserID == nil || userID == nil || *puzzle.CreatorUserID != *userID) {
+		return HintResponse{}, ErrNotFound
+	}
+
+	size := puzzle.gridSize()
+	values, _, _, err := normalizeValuesAgainstGivens(puzzle.Givens, req.Values, size)
+	if err != nil {
+		return HintResponse{}, err
+	}
+	cornerNotes, err := normalizeHintNotes(req.CornerNotes, puzzle.Givens, size)
+	if err != nil {
+		return HintResponse{}, err
+	}
+	centerNotes, err := normalizeHintNotes(req.CenterNotes, puzzle.Givens, size)
+	if err != nil {
+		return HintResponse{}, err
+	}
+
+	variant, err := puzzle.variant()
+	if err != nil {
+		return HintResponse{Available: false, Reason: "invalid_givens"}, nil
+	}
+	// The technique engine only knows the 9x9 grid with classic boxes.
+	if size != 9 || !variant.HasClassicBoxes() {
+		return HintResponse{Available: false, Reason: "variant_not_supported"}, nil
+	}
+	_, givensGrid, err := solver.ParseAndNormalize(puzzle.Givens)
+	if err != nil {
+		return HintResponse{Available: false, Reason: "invalid_givens"}, nil
+	}
+	solveCtx, cancel := context.WithTimeout(ctx, solveTimeout)
+	solutions, err := variant.FindSolutionsContext(solveCtx, givensGrid, 2)
+	cancel()
+	if errors.Is(err, solver.ErrTimeout) {
+		return HintResponse{}, err
+	}
+	if err != nil || len(solutions) != 1 {
+		return HintResponse{Available: false, Reason: "puzzle_not_unique"}, nil
+	}
+	solution := solutions[0]
+
+	var grid solver.Grid
+	for i := 0; i < 81; i++ {
+		grid[i] = values[i] - '0'
+	}
+
+	// Never hint from a broken state: point at the mistake instead.
+	if a, b, ok := solver.FindConflict(grid); ok {
+		if givensGrid[a] != 0 {
+			a, b = b, a
+		}
+		return HintResponse{
+			Available:    false,
+			Reason:       "conflict",
+			MistakeCells: []solver.AffectedCell{cellAt(a), cellAt(b)},
+		}, nil
+	}
+	for i := 0; i < 81; i++ {
+		if grid[i] != 0 && grid[i] != solution[i] {
+			return HintResponse{
+				Available:    false,
+				Reason:       "wrong_value",
+				MistakeCells: []solver.AffectedCell{cellAt(i)},
+			}, nil
+		}
+	}
+	for i := 0; i < 81; i++ {
+		notes := cornerNotes[i] | centerNotes[i]
+		if grid[i] == 0 && notes != 0 && notes&(1<<(solution[i]-1)) == 0 {
+			return HintResponse{
+				Available:    false,
+				Reason:       "wrong_note",
+				MistakeCells: []solver.AffectedCell{cellAt(i)},
+			}, nil
+		}
+	}
+
+	if grid == solution {
+		return HintResponse{Available: false, Reason: "solved"}, nil
+	}
+
+	engine := solver.NewEngine(grid)
+	for i := 0; i < 81; i++ {
+		if notes := cornerNotes[i] | centerNotes[i]; grid[i] == 0 && notes != 0 {
+			engine.RestrictCandidates(i, uint16(notes)<<1)
+		}
+	}
+	step := engine.FindStep()
+	if step == nil {
+		return HintResponse{Available: false, Reason: "no_logical_step"}, nil
+	}
+
+	level := solver.HintLevel(req.Level)
+	if level < solver.HintLevelRegion {
+		level = solver.HintLevelRegion
+	}
+	if level > solver.HintLevelFull {
+		level = solver.HintLevelFull
+	}
+
+	resp := HintResponse{Available: true, Hint: step.Hint().AtLevel(level)}
+	if userID != nil {
+		maxLevel, err := s.recordHintLevel(ctx, puzzleID, *userID, int(level))
+		if err != nil {
+			return HintResponse{}, err
+		}
+		resp.MaxLevelReached = maxLevel
+	}
+
+	return resp, nil
+}
+
+// recordHintLevel stores the deepest hint level a user has revealed and returns it.
+func (s *Service) recordHintLevel(ctx context.Context, puzzleID uint, userID uint, level int) (int, error) {
+	usage := PuzzleHintUsage{
+		PuzzleID: puzzleID,
+		UserID:   userID,
+		MaxLevel: level,
+	}
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "puzzle_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"max_level":  gorm.Expr("CASE WHEN excluded.max_level > puzzle_hint_usages.max_level THEN excluded.max_level ELSE puzzle_hint_usages.max_level END"),
+				"updated_at": gorm.Expr("excluded.updated_at"),
+			}),
+		}).
+		Create(&usage).Error
+	if err != nil {
+		return 0, errors.New("db_insert_failed")
+	}
+
+	if err := s.db.WithContext(ctx).
+		Where("puzzle_id = ? AND user_id = ?", puzzleID, userID).
+		First(&usage).Error; err != nil {
+		return 0, errors.New("db_query_failed")
+	}
+	return usage.MaxLevel, nil
+}
+
+// OptimizeRequest contains a uniquely solvable grid and the difficulty (1-10) to aim for.
+type OptimizeRequest struct {
+	Givens           string `json:"givens"`
+	TargetDifficulty int    `json:"targetDifficulty"`
 }
 
 // OptimizeResponse contains the response for optimization requests.
 type OptimizeResponse struct {
-	Available bool   `json:"available"`
-	Reason    string `json:"reason,omitempty"`
+	Available    bool    `json:"available"`
+	Reason       string  `json:"reason,omitempty"`
+	Givens       string  `json:"givens,omitempty"`
+	Difficulty   int     `json:"difficulty,omitempty"`
+	SERating     float64 `json:"seRating,omitempty"`
+	RemovedCount int     `json:"removedCount"`
+}
+
+// Optimize removes givens to push the puzzle toward the target difficulty while keeping
+// its solution unique. It returns solver.ErrTimeout when optimizing takes longer than
+// solveTimeout or ctx is cancelled.
+func (s *Service) Optimize(ctx context.Context, req OptimizeRequest) (OptimizeResponse, error) {
+	_, grid, err := solver.ParseAndNormalize(req.Givens)
+	if err != nil {
+		return OptimizeResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, solveTimeout)
+	defer cancel()
+	result, err := s.optimizer.OptimizeDifficulty(ctx, grid, req.TargetDifficulty)
+	if err != nil {
+		return OptimizeResponse{}, err
+	}
+
+	resp := OptimizeResponse{
+		Available:    true,
+		Givens:       result.Grid.String(),
+		Difficulty:   result.Difficulty,
+		SERating:     result.SERating,
+		RemovedCount: result.Removed,
+	}
+	if result.Difficulty < req.TargetDifficulty {
+		resp.Reason = "target_not_reached"
+	}
+	return resp, nil
+}
+
+const maxGenerateCount = 10
+
+// GenerateRequest describes the puzzles to generate. An empty band means any difficulty.
+type GenerateRequest struct {
+	Title         *string            `json:"title"`
+	Symmetry      generator.Symmetry `json:"symmetry"`
+	MinDifficulty int                `json:"minDifficulty"`
+	MaxDifficulty int                `json:"maxDifficulty"`
+	Count         int                `json:"count"`
+}
+
+// GeneratedPuzzle is a generated puzzle saved as a draft.
+type GeneratedPuzzle struct {
+	ID         uint    `json:"id"`
+	Givens     string  `json:"givens"`
+	Difficulty int     `json:"difficulty"`
+	SERating   float64 `json:"seRating"`
+	Minimal    bool    `json:"minimal"`
+}
+
+// GenerateResponse lists the drafts created by Generate.
+type GenerateResponse struct {
+	Items []GeneratedPuzzle `json:"items"`
+}
+
+// Generate creates uniquely solvable puzzles in the requested band and saves them as drafts.
+func (s *Service) Generate(ctx context.Context, creatorUserID uint, req GenerateRequest) (GenerateResponse, error) {
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+	if count > maxGenerateCount {
+		return GenerateResponse{}, errors.New("invalid_count")
+	}
+	opts := generator.Options{
+		Symmetry:      req.Symmetry,
+		MinDifficulty: req.MinDifficulty,
+		MaxDifficulty: req.MaxDifficulty,
+		Workers:       runtime.GOMAXPROCS(0),
+	}
+	if opts.MinDifficulty == 0 {
+		opts.MinDifficulty = 1
+	}
+	if opts.MaxDifficulty == 0 {
+		opts.MaxDifficulty = 10
+	}
+
+	resp := GenerateResponse{Items: make([]GeneratedPuzzle, 0, count)}
+	for i := 0; i < count; i++ {
+		p, err := generator.Generate(ctx, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return GenerateResponse{}, errors.New("generate_cancelled")
+			}
+			return GenerateResponse{}, err
+		}
+
+		givens := p.Givens.String()
+		created, err := s.Create(ctx, creatorUserID, CreatePuzzleRequest{
+			Title:                      req.Title,
+			Givens:                     givens,
+			CreatorSuggestedDifficulty: p.Difficulty,
+		})
+		if err != nil {
+			return GenerateResponse{}, err
+		}
+		resp.Items = append(resp.Items, GeneratedPuzzle{
+			ID:         created.ID,
+			Givens:     givens,
+			Difficulty: p.Difficulty,
+			SERating:   p.SERating,
+			Minimal:    p.Minimal,
+		})
+	}
+	return resp, nil
+}
+
+// aggregatedDifficulty prefers the players' average vote, then the server-computed rating,
+// then the creator's suggestion.
+func aggregatedDifficulty(row puzzleStatsRow) int {
+	base := row.CreatorSuggestedDifficulty
+	if row.ComputedDifficulty != nil && *row.ComputedDifficulty > 0 {
+		base = *row.ComputedDifficulty
+	}
+	if row.DifficultyAvg != nil && !math.IsNaN(*row.DifficultyAvg) {
+		if agg := int(math.Round(*row.DifficultyAvg)); agg > 0 {
+			return agg
+		}
+	}
+	return base
 }
 
 func httpStatusFromError(err error) int {
 	if errors.Is(err, ErrNotFound) {
 		return http.StatusNotFound
 	}
+	if errors.Is(err, solver.ErrTimeout) {
+		return http.StatusServiceUnavailable
+	}
 	return http.StatusBadRequest
 }
 
@@ -607,6 +1852,115 @@ func boolPtr(v bool) *bool {
 	return &b
 }
 
+// encodeCages checks the cage layout and returns it as JSON, or nil for a classic puzzle.
+// Cage sums are not checked against the givens until validate or publish.
+func encodeCages(cages []solver.Cage) ([]byte, error) {
+	if len(cages) == 0 {
+		return nil, nil
+	}
+	if err := solver.ValidateCages(solver.Grid{}, cages); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(cages)
+	if err != nil {
+		return nil, errors.New("invalid_cages")
+	}
+	return raw, nil
+}
+
+// encodeGeometry checks the lines and returns them as JSON, or nil when there are none.
+func encodeGeometry(geo solver.Geometry) ([]byte, error) {
+	if geo.IsEmpty() {
+		return nil, nil
+	}
+	if err := solver.ValidateGeometry(geo); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(geo)
+	if err != nil {
+		return nil, errors.New("invalid_geometry")
+	}
+	return raw, nil
+}
+
+// variant returns the rules stored with the puzzle.
+func (p *Puzzle) variant() (solver.Variant, error) {
+	v := solver.Variant{Rules: splitRules(p.Rules), Regions: p.Regions}
+	if len(p.Cages) > 0 {
+		if err := json.Unmarshal(p.Cages, &v.Cages); err != nil {
+			return solver.Variant{}, errors.New("invalid_cages")
+		}
+	}
+	if len(p.Geometry) > 0 {
+		if err := json.Unmarshal(p.Geometry, &v.Geometry); err != nil {
+			return solver.Variant{}, errors.New("invalid_geometry")
+		}
+	}
+	return v, nil
+}
+
+// fingerprint returns the puzzle's solver.Fingerprint, or nil unless it is a classic 9x9
+// puzzle. Variant rules, cages and regions are not preserved by the symmetries the
+// canonical form quotients out.
+func (p *Puzzle) fingerprint() *string {
+	if p.gridSize() != 9 {
+		return nil
+	}
+	v, err := p.variant()
+	if err != nil || !v.IsClassic() {
+		return nil
+	}
+	_, grid, err := solver.ParseGrid(p.Givens)
+	if err != nil {
+		return nil
+	}
+	fingerprint := solver.Fingerprint(grid)
+	return &fingerprint
+}
+
+// normalizeRegions validates a jigsaw region map; empty means classic boxes.
+func normalizeRegions(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	return solver.NormalizeRegions(raw)
+}
+
+// normalizeSize defaults an unset grid size to 9 and rejects unsupported ones.
+func normalizeSize(size int) (int, error) {
+	if size == 0 {
+		return 9, nil
+	}
+	if !solver.ValidSize(size) {
+		return 0, errors.New("invalid_size")
+	}
+	return size, nil
+}
+
+// gridSize returns the puzzle's grid size; puzzles saved before sizes existed are 9x9.
+func (p *Puzzle) gridSize() int {
+	if p.Size == 0 {
+		return 9
+	}
+	return p.Size
+}
+
+// checkVariantSize rejects variant rules on grids other than 9x9.
+func checkVariantSize(size int, v solver.Variant) error {
+	if size != 9 && !v.IsClassic() {
+		return errors.New("variant_requires_9x9")
+	}
+	return nil
+}
+
+// splitRules turns the stored comma-separated rule set into names.
+func splitRules(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
 func normalizeTitle(raw *string) *string {
 	if raw == nil {
 		return nil
@@ -618,30 +1972,25 @@ func normalizeTitle(raw *string) *string {
 	return &t
 }
 
-func normalizeDraftGivens(raw string) string {
+// normalizeDraftGivens keeps the digits of a size x size draft, dropping other
+// characters, and pads it with empty cells.
+func normalizeDraftGivens(raw string, size int) string {
+	n := size * size
 	s := strings.TrimSpace(raw)
-	if s == "" {
-		return strings.Repeat("0", 81)
-	}
 
 	var b strings.Builder
-	b.Grow(81)
+	b.Grow(n)
 	count := 0
-	for i := 0; i < len(s) && count < 81; i++ {
-		ch := s[i]
-		switch {
-		case ch >= '0' && ch <= '9':
-			b.WriteByte(ch)
-			count++
-		case ch == '.':
-			b.WriteByte('0')
-			count++
-		default:
-			// skip
+	for i := 0; i < len(s) && count < n; i++ {
+		v, ok := solver.ParseDigit(size, s[i])
+		if !ok {
+			continue
 		}
+		b.WriteByte(solver.DigitChar(size, v))
+		count++
 	}
-	for count < 81 {
-		b.WriteByte('0')
+	for count < n {
+		b.WriteByte(solver.EmptyChar(size))
 		count++
 	}
 	return b.String()
@@ -661,7 +2010,10 @@ func (s *Service) ListMine(ctx context.Context, userID uint) (MyPuzzlesResponse,
 			p.id as id,
 			p.title as title,
 			p.givens as givens,
+			p.size as size,
 			p.creator_suggested_difficulty as creator_suggested_difficulty,
+			p.computed_difficulty as computed_difficulty,
+			p.se_rating as se_rating,
 			p.creator_user_id as creator_user_id,
 			p.published as published,
 			p.created_at as created_at,
@@ -680,19 +2032,16 @@ func (s *Service) ListMine(ctx context.Context, userID uint) (MyPuzzlesResponse,
 
 	items := make([]PuzzleSummary, 0, len(rows))
 	for _, p := range rows {
-		agg := p.CreatorSuggestedDifficulty
-		if p.DifficultyAvg != nil && !math.IsNaN(*p.DifficultyAvg) {
-			agg = int(math.Round(*p.DifficultyAvg))
-			if agg <= 0 {
-				agg = p.CreatorSuggestedDifficulty
-			}
-		}
+		agg := aggregatedDifficulty(p)
 
 		items = append(items, PuzzleSummary{
 			ID:                         p.ID,
 			Title:                      p.Title,
 			Givens:                     p.Givens,
+			Size:                       p.Size,
 			CreatorSuggestedDifficulty: p.CreatorSuggestedDifficulty,
+			ComputedDifficulty:         p.ComputedDifficulty,
+			SERating:                   p.SERating,
 			AggregatedDifficulty:       agg,
 			Published:                  p.Published,
 			Likes:                      p.Likes,
@@ -775,7 +2124,7 @@ func (s *Service) GetProgress(ctx context.Context, puzzleID uint, userID uint) (
 // SaveProgress saves puzzle progress for a user.
 func (s *Service) SaveProgress(ctx context.Context, puzzleID uint, userID uint, req SaveProgressRequest) (ProgressResponse, error) {
 	var puzzle Puzzle
-	if err := s.db.WithContext(ctx).Select("id", "givens", "creator_user_id", "published").First(&puzzle, puzzleID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Select("id", "givens", "size", "creator_user_id", "published").First(&puzzle, puzzleID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ProgressResponse{}, ErrNotFound
 		}
@@ -785,22 +2134,23 @@ func (s *Service) SaveProgress(ctx context.Context, puzzleID uint, userID uint,
 		return ProgressResponse{}, ErrNotFound
 	}
 
-	values, filled, total, err := normalizeValuesAgainstGivens(puzzle.Givens, req.Values)
+	size := puzzle.gridSize()
+	values, filled, total, err := normalizeValuesAgainstGivens(puzzle.Givens, req.Values, size)
 	if err != nil {
 		return ProgressResponse{}, err
 	}
 
-	cornerNotes, err := normalizeNotes(req.CornerNotes, puzzle.Givens)
+	cornerNotes, err := normalizeNotes(req.CornerNotes, puzzle.Givens, size)
 	if err != nil {
 		return ProgressResponse{}, err
 	}
-	centerNotes, err := normalizeNotes(req.CenterNotes, puzzle.Givens)
+	centerNotes, err := normalizeNotes(req.CenterNotes, puzzle.Givens, size)
 	if err != nil {
 		return ProgressResponse{}, err
 	}
 
 	notesEmpty := true
-	for i := 0; i < 81; i++ {
+	for i := range cornerNotes {
 		if (cornerNotes[i] | centerNotes[i]) != 0 {
 			notesEmpty = false
 			break
@@ -896,36 +2246,39 @@ func (s *Service) ClearProgress(ctx context.Context, puzzleID uint, userID uint)
 	return nil
 }
 
-func normalizeValuesAgainstGivens(givens string, values string) (string, int, int, error) {
-	if len(givens) != 81 {
+// normalizeValuesAgainstGivens checks a player's values against the givens of a size x
+// size grid, keeping the givens, and returns the values with the filled and fillable
+// cell counts.
+func normalizeValuesAgainstGivens(givens string, values string, size int) (string, int, int, error) {
+	n := size * size
+	if len(givens) != n {
 		return "", 0, 0, errors.New("invalid_givens")
 	}
-	if len(values) != 81 {
+	if len(values) != n {
 		return "", 0, 0, errors.New("invalid_values")
 	}
 
 	total := 0
 	filled := 0
-	out := make([]byte, 81)
-	for i := 0; i < 81; i++ {
-		g := givens[i]
-		v := values[i]
-
-		if g < '0' || g > '9' {
+	out := make([]byte, n)
+	for i := 0; i < n; i++ {
+		g, ok := solver.ParseDigit(size, givens[i])
+		if !ok {
 			return "", 0, 0, errors.New("invalid_givens")
 		}
-		if v < '0' || v > '9' {
+		v, ok := solver.ParseDigit(size, values[i])
+		if !ok {
 			return "", 0, 0, errors.New("invalid_values")
 		}
 
-		if g != '0' {
-			out[i] = g
+		if g != 0 {
+			out[i] = solver.DigitChar(size, g)
 			continue
 		}
 
 		total++
-		out[i] = v
-		if v != '0' {
+		out[i] = solver.DigitChar(size, v)
+		if v != 0 {
 			filled++
 		}
 	}
@@ -933,23 +2286,41 @@ func normalizeValuesAgainstGivens(givens string, values string) (string, int, in
 	return string(out), filled, total, nil
 }
 
-func normalizeNotes(notes []int, givens string) ([]int, error) {
-	if len(notes) != 81 {
+// normalizeHintNotes is like normalizeNotes but treats missing notes as empty.
+func normalizeHintNotes(notes []int, givens string, size int) ([]int, error) {
+	if len(notes) == 0 {
+		return make([]int, size*size), nil
+	}
+	return normalizeNotes(notes, givens, size)
+}
+
+func cellAt(idx int) solver.AffectedCell {
+	return solver.AffectedCell{Row: idx / 9, Col: idx % 9}
+}
+
+// normalizeNotes checks pencil marks (bit d-1 for digit d) and clears them on givens.
+func normalizeNotes(notes []int, givens string, size int) ([]int, error) {
+	n := size * size
+	if len(notes) != n {
 		return nil, errors.New("invalid_notes")
 	}
-	if len(givens) != 81 {
+	if len(givens) != n {
 		return nil, errors.New("invalid_givens")
 	}
 
-	out := make([]int, 81)
-	for i := 0; i < 81; i++ {
-		g := givens[i]
-		if g != '0' {
+	maxMask := 1<<size - 1
+	out := make([]int, n)
+	for i := 0; i < n; i++ {
+		g, ok := solver.ParseDigit(size, givens[i])
+		if !ok {
+			return nil, errors.New("invalid_givens")
+		}
+		if g != 0 {
 			out[i] = 0
 			continue
 		}
 		v := notes[i]
-		if v < 0 || v > 0b111111111 {
+		if v < 0 || v > maxMask {
 			return nil, errors.New("invalid_notes")
 		}
 		out[i] = v
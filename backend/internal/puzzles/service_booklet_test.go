@@ -0,0 +1,51 @@
+package puzzles
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestBooklet_PrintsPuzzlesAndAnswerKey(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+	ctx := context.Background()
+
+	creatorID := uint(22)
+	title := "Club week 7"
+	unique, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: classicGivens, Title: &title})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ambiguous, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: ambiguousGivens})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ids := []uint{unique.ID, ambiguous.ID}
+
+	if _, err := svc.Booklet(ctx, nil, BookletRequest{IDs: ids}); err != ErrNotFound {
+		t.Fatalf("expected drafts to be hidden from others, got %v", err)
+	}
+
+	file, err := svc.Booklet(ctx, &creatorID, BookletRequest{IDs: ids, Title: "Weekly packet", PerPage: 1})
+	if err != nil {
+		t.Fatalf("booklet: %v", err)
+	}
+	if file.ContentType != "application/pdf" || !bytes.HasPrefix(file.Body, []byte("%PDF-")) {
+		t.Fatalf("expected a PDF, got %s %.8q", file.ContentType, file.Body)
+	}
+	// One page per puzzle and an answer key with only the unique one.
+	if n := bytes.Count(file.Body, []byte("/Type /Page ")); n != 3 {
+		t.Fatalf("expected 3 pages, got %d", n)
+	}
+
+	if _, err := svc.Booklet(ctx, &creatorID, BookletRequest{IDs: ids, PerPage: 5}); err == nil || err.Error() != "invalid_per_page" {
+		t.Fatalf("expected invalid_per_page, got %v", err)
+	}
+	missing := 999
+	if _, err := svc.Booklet(ctx, &creatorID, BookletRequest{Filter: ListRequest{Difficulty: &missing}}); err == nil || err.Error() != "no_puzzles" {
+		t.Fatalf("expected no_puzzles for an empty filter, got %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
+
+	"sudoku/backend/internal/auth"
 )
 
 func newTestDB(t *testing.T) *gorm.DB {
@@ -16,6 +18,9 @@ func newTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
+	if err := auth.AutoMigrate(db); err != nil {
+		t.Fatalf("automigrate auth: %v", err)
+	}
 	if err := AutoMigrate(db); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
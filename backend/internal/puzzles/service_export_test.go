@@ -0,0 +1,95 @@
+package puzzles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sudoku/backend/internal/auth"
+	"sudoku/backend/internal/solver"
+)
+
+func TestExport_WritesFormatsThatImportReads(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+	ctx := context.Background()
+
+	name := "Setter"
+	creator := auth.User{ID: 19, Email: "export-setter@example.com", DisplayName: &name}
+	if err := db.Create(&creator).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	title := "Export & friends"
+	first, err := svc.Create(ctx, creator.ID, CreatePuzzleRequest{Givens: classicGivens, Title: &title})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	second, err := svc.Create(ctx, creator.ID, CreatePuzzleRequest{Givens: ambiguousGivens})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ids := []uint{first.ID, second.ID}
+
+	for _, format := range []string{"flat", "sdk", "ss", "opensudoku"} {
+		file, err := svc.Export(ctx, ids, &creator.ID, format)
+		if err != nil {
+			t.Fatalf("%s: export: %v", format, err)
+		}
+		if !strings.HasSuffix(file.Filename, "."+exportFormats[format][0]) {
+			t.Fatalf("%s: unexpected filename %q", format, file.Filename)
+		}
+		imported, err := svc.Import(ctx, ImportRequest{Data: string(file.Body)})
+		if err != nil {
+			t.Fatalf("%s: import: %v", format, err)
+		}
+		if imported.Format != format || len(imported.Puzzles) != 2 ||
+			imported.Puzzles[0].Givens != classicGivens || imported.Puzzles[1].Givens != ambiguousGivens {
+			t.Fatalf("%s: round trip lost puzzles: %+v", format, imported)
+		}
+		if format != "ss" && imported.Puzzles[0].Title != title {
+			t.Fatalf("%s: round trip lost the title: %+v", format, imported.Puzzles[0])
+		}
+	}
+
+	file, err := svc.Export(ctx, ids[:1], &creator.ID, "json")
+	if err != nil {
+		t.Fatalf("json: export: %v", err)
+	}
+	if file.Filename != fmt.Sprintf("puzzle-%d.json", first.ID) {
+		t.Fatalf("unexpected filename %q", file.Filename)
+	}
+	var bundle ExportBundle
+	if err := json.Unmarshal(file.Body, &bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if len(bundle.Puzzles) != 1 || bundle.Puzzles[0].Title == nil || *bundle.Puzzles[0].Title != title {
+		t.Fatalf("unexpected bundle %+v", bundle)
+	}
+	if c := bundle.Puzzles[0].Creator; c == nil || c.ID != creator.ID || c.DisplayName == nil || *c.DisplayName != name {
+		t.Fatalf("expected the creator in the bundle, got %+v", c)
+	}
+
+	other := uint(20)
+	if _, err := svc.Export(ctx, ids, &other, "flat"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected drafts to be hidden from other users, got %v", err)
+	}
+	if _, err := svc.Export(ctx, ids, &creator.ID, "pdf"); err == nil || err.Error() != "unknown_format" {
+		t.Fatalf("expected unknown_format, got %v", err)
+	}
+
+	diagonal, err := svc.Create(ctx, creator.ID, CreatePuzzleRequest{Givens: classicGivens, Rules: []string{solver.RuleDiagonal}})
+	if err != nil {
+		t.Fatalf("create variant: %v", err)
+	}
+	if _, err := svc.Export(ctx, []uint{diagonal.ID}, &creator.ID, "sdk"); err == nil || err.Error() != "format_requires_classic" {
+		t.Fatalf("expected format_requires_classic, got %v", err)
+	}
+	if _, err := svc.Export(ctx, []uint{diagonal.ID}, &creator.ID, "json"); err != nil {
+		t.Fatalf("expected the JSON bundle to hold variants, got %v", err)
+	}
+}
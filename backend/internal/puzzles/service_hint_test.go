@@ -0,0 +1,90 @@
+package puzzles
+
+import (
+	"context"
+	"testing"
+)
+
+const classicGivens = "530070000600195000098000060800060003400803001700020006060000280000419005000080079"
+
+func TestHint_ReturnsStepForCurrentValues(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	puzzle := Puzzle{Givens: classicGivens, CreatorSuggestedDifficulty: 1, Published: true}
+	if err := db.Create(&puzzle).Error; err != nil {
+		t.Fatalf("insert puzzle: %v", err)
+	}
+
+	resp, err := svc.Hint(context.Background(), puzzle.ID, nil, HintRequest{Values: classicGivens, Level: 4})
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if !resp.Available || resp.Hint == nil {
+		t.Fatalf("expected a hint, got %+v", resp)
+	}
+	if len(resp.Hint.AffectedCells) == 0 || len(resp.Hint.Placements) == 0 {
+		t.Fatalf("expected affected cells and a placement, got %+v", resp.Hint)
+	}
+}
+
+func TestHint_RevealsByLevelAndRecordsMaxLevel(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	puzzle := Puzzle{Givens: classicGivens, CreatorSuggestedDifficulty: 1, Published: true}
+	if err := db.Create(&puzzle).Error; err != nil {
+		t.Fatalf("insert puzzle: %v", err)
+	}
+
+	userID := uint(7)
+	resp, err := svc.Hint(context.Background(), puzzle.ID, &userID, HintRequest{Values: classicGivens, Level: 2})
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if resp.Hint.Technique == "" || len(resp.Hint.AffectedCells) != 0 {
+		t.Fatalf("level 2 should name the technique only, got %+v", resp.Hint)
+	}
+
+	resp, err = svc.Hint(context.Background(), puzzle.ID, &userID, HintRequest{Values: classicGivens})
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if resp.Hint.Level != 1 || resp.Hint.Technique != "" || resp.Hint.Region == "" {
+		t.Fatalf("default level should be a region nudge, got %+v", resp.Hint)
+	}
+	if resp.MaxLevelReached != 2 {
+		t.Fatalf("expected max level 2 to be kept, got %d", resp.MaxLevelReached)
+	}
+}
+
+func TestHint_PointsAtWrongValue(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	puzzle := Puzzle{Givens: classicGivens, CreatorSuggestedDifficulty: 1, Published: true}
+	if err := db.Create(&puzzle).Error; err != nil {
+		t.Fatalf("insert puzzle: %v", err)
+	}
+
+	// R1C3 is 4 in the solution; 1 does not clash with any peer but is still wrong.
+	values := []byte(classicGivens)
+	values[2] = '1'
+
+	resp, err := svc.Hint(context.Background(), puzzle.ID, nil, HintRequest{Values: string(values)})
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if resp.Available || resp.Reason != "wrong_value" {
+		t.Fatalf("expected wrong_value, got %+v", resp)
+	}
+	if len(resp.MistakeCells) != 1 || resp.MistakeCells[0].Row != 0 || resp.MistakeCells[0].Col != 2 {
+		t.Fatalf("expected mistake at R1C3, got %+v", resp.MistakeCells)
+	}
+}
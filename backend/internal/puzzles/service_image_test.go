@@ -0,0 +1,62 @@
+package puzzles
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sudoku/backend/internal/solver"
+)
+
+func TestImage_AddsProgressAndSolution(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+	ctx := context.Background()
+
+	creatorID := uint(21)
+	created, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: classicGivens})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := svc.Image(ctx, created.ID, nil, ImageOptions{}); err != ErrNotFound {
+		t.Fatalf("expected a draft to be hidden from others, got %v", err)
+	}
+
+	values := []byte(classicGivens)
+	values[2] = '4'
+	notes := make([]int, 81)
+	notes[3] = 0b110
+	if _, err := svc.SaveProgress(ctx, created.ID, creatorID, SaveProgressRequest{Values: string(values), CornerNotes: make([]int, 81), CenterNotes: notes}); err != nil {
+		t.Fatalf("save progress: %v", err)
+	}
+
+	img, err := svc.Image(ctx, created.ID, &creatorID, ImageOptions{Progress: true, Solution: true})
+	if err != nil {
+		t.Fatalf("image: %v", err)
+	}
+	if img.Size != 9 || img.Givens[0] != 5 || img.Givens[2] != 0 {
+		t.Fatalf("unexpected givens: %v", img.Givens)
+	}
+	if img.Values[2] != 4 || img.Notes[3] != 0b110 {
+		t.Fatalf("expected saved progress, got values %v notes %v", img.Values, img.Notes)
+	}
+	_, grid, _ := solver.ParseGrid(classicGivens)
+	solutions, err := solver.FindSolutions(grid, 1)
+	if err != nil || len(solutions) != 1 || string(img.Solution) != string(solutions[0][:]) {
+		t.Fatalf("expected the solution, got %v", img.Solution)
+	}
+
+	ambiguous, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: strings.Replace(classicGivens, "53", "00", 1)})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Image(ctx, ambiguous.ID, &creatorID, ImageOptions{Solution: true}); err == nil || err.Error() != "puzzle_not_unique" {
+		t.Fatalf("expected puzzle_not_unique, got %v", err)
+	}
+	if _, err := svc.Image(ctx, created.ID, nil, ImageOptions{Progress: true}); err != ErrNotFound {
+		t.Fatalf("expected not_found without a user, got %v", err)
+	}
+}
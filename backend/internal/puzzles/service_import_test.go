@@ -0,0 +1,162 @@
+package puzzles
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestImport_DetectsFormatAndReportsConflicts(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+
+	conflict := "55" + classicGivens[2:]
+	resp, err := svc.Import(context.Background(), ImportRequest{Data: classicGivens + " First\n" + conflict + "\n"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if resp.Format != "flat" || len(resp.Puzzles) != 2 {
+		t.Fatalf("expected two flat puzzles, got %+v", resp)
+	}
+	if resp.Puzzles[0].Givens != classicGivens || resp.Puzzles[0].Title != "First" || len(resp.Puzzles[0].Errors) != 0 {
+		t.Fatalf("unexpected first puzzle %+v", resp.Puzzles[0])
+	}
+	if len(resp.Puzzles[1].Errors) != 1 || resp.Puzzles[1].Errors[0] != "row_conflict" {
+		t.Fatalf("expected a row conflict, got %+v", resp.Puzzles[1])
+	}
+
+	var wiki strings.Builder
+	for i := 0; i < 81; i++ {
+		if d := classicGivens[i]; d != '0' {
+			wiki.WriteString(strings.Repeat("0", int(d-'1')) + string(d) + strings.Repeat("0", int('9'-d)))
+		} else {
+			wiki.WriteString("120000000")
+		}
+	}
+	resp, err = svc.Import(context.Background(), ImportRequest{Format: "candidates", Data: wiki.String()})
+	if err != nil {
+		t.Fatalf("import candidates: %v", err)
+	}
+	p := resp.Puzzles[0]
+	if p.Givens != classicGivens || len(p.CenterNotes) != 81 || p.CenterNotes[2] != 0b11 || p.CenterNotes[0] != 0 {
+		t.Fatalf("unexpected candidate import %+v", p)
+	}
+
+	if _, err := svc.Import(context.Background(), ImportRequest{Format: "sdk", Data: "123"}); err == nil || err.Error() != "invalid_sdk" {
+		t.Fatalf("expected invalid_sdk, got %v", err)
+	}
+}
+
+func TestBulkImport_ReportsEachLine(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+	creatorID := uint(18)
+	ctx := context.Background()
+
+	const first = "000000012000035000000600070700000300000400800100000000000120000080000040050000600"
+	const second = "000000012003600000000007000410020000000500300700000600280000040000300500000000000"
+	var transposed [81]byte
+	for i := 0; i < 81; i++ {
+		transposed[i%9*9+i/9] = first[i]
+	}
+	file := strings.Join([]string{
+		"# A small collection",
+		first + " #Opening",
+		"",
+		string(transposed[:]),
+		ambiguousGivens,
+		"12345",
+		second,
+	}, "\n")
+
+	var results []BulkImportResult
+	err := svc.BulkImport(ctx, creatorID, strings.NewReader(file), func(r BulkImportResult) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("bulk import: %v", err)
+	}
+
+	want := []struct {
+		line   int
+		status string
+	}{
+		{2, BulkCreated},
+		{4, BulkDuplicate},
+		{5, BulkNotUnique},
+		{6, BulkParseError},
+		{7, BulkCreated},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for i, w := range want {
+		if results[i].Line != w.line || results[i].Status != w.status {
+			t.Fatalf("result %d: expected line %d %s, got %+v", i, w.line, w.status, results[i])
+		}
+	}
+	if results[1].DuplicateOf != results[0].PuzzleID {
+		t.Fatalf("expected line 4 to repeat line 2's draft, got %+v", results[1])
+	}
+	if results[3].Error != "givens_must_be_81_chars" {
+		t.Fatalf("expected a parse error, got %+v", results[3])
+	}
+
+	detail, err := svc.Get(ctx, results[0].PuzzleID, &creatorID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if detail.Title == nil || *detail.Title != "Opening" || detail.Published {
+		t.Fatalf("expected the titled draft, got %+v", detail)
+	}
+}
+
+// lineReader hands out one line per Read and counts the reads made after done is set.
+type lineReader struct {
+	lines     []string
+	done      atomic.Bool
+	lateReads atomic.Int32
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if r.done.Load() {
+		r.lateReads.Add(1)
+	}
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.lines[0]+"\n")
+	r.lines = r.lines[1:]
+	return n, nil
+}
+
+func TestBulkImport_StopsReadingWhenEmitFails(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+	r := &lineReader{}
+	for i := 0; i < 200; i++ {
+		r.lines = append(r.lines, "12345")
+	}
+
+	gone := errors.New("client_gone")
+	err := svc.BulkImport(context.Background(), 19, r, func(BulkImportResult) error {
+		return gone
+	})
+	r.done.Store(true)
+	if !errors.Is(err, gone) {
+		t.Fatalf("expected the emit error, got %v", err)
+	}
+	if len(r.lines) == 0 {
+		t.Fatalf("expected the import to stop before the end of the file")
+	}
+	if n := r.lateReads.Load(); n != 0 {
+		t.Fatalf("expected no reads after returning, got %d", n)
+	}
+}
@@ -0,0 +1,105 @@
+package puzzles
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"sudoku/backend/internal/auth"
+)
+
+func TestList_FiltersAndSortsBySERating(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	for _, se := range []float64{9.4, 9.1, 9.7, 1.2} {
+		rating := se
+		puzzle := Puzzle{
+			Givens:                     strings.Repeat("0", 81),
+			CreatorSuggestedDifficulty: 10,
+			SERating:                   &rating,
+			Published:                  true,
+		}
+		if err := db.Create(&puzzle).Error; err != nil {
+			t.Fatalf("insert puzzle: %v", err)
+		}
+	}
+
+	minSE := 9.0
+	resp, err := svc.List(context.Background(), ListRequest{MinSERating: &minSE, Sort: "se_desc"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(resp.Items) != 3 {
+		t.Fatalf("expected 3 puzzles rated 9.0+, got %d", len(resp.Items))
+	}
+	want := []float64{9.7, 9.4, 9.1}
+	for i, item := range resp.Items {
+		if item.SERating == nil || *item.SERating != want[i] {
+			t.Fatalf("item %d: expected SE %.1f, got %v", i, want[i], item.SERating)
+		}
+	}
+}
+
+func TestAutoMigrate_RatesPuzzlesPublishedBeforeRatings(t *testing.T) {
+	t.Parallel()
+
+	// A database of its own, since the rating columns are dropped to look like an
+	// install from before ratings.
+	db, err := gorm.Open(sqlite.Open("file:ratings_backfill?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := auth.AutoMigrate(db); err != nil {
+		t.Fatalf("automigrate auth: %v", err)
+	}
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	svc := NewService(db)
+
+	legacy := Puzzle{Givens: classicGivens, CreatorSuggestedDifficulty: 5, Published: true}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatalf("insert puzzle: %v", err)
+	}
+	for _, column := range []string{"ComputedDifficulty", "SERating"} {
+		if err := db.Migrator().DropColumn(&Puzzle{}, column); err != nil {
+			t.Fatalf("drop %s: %v", column, err)
+		}
+	}
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+
+	detail, err := svc.Get(context.Background(), legacy.ID, nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if detail.ComputedDifficulty == nil || detail.SERating == nil {
+		t.Fatalf("expected the legacy puzzle to be rated, got %+v", detail)
+	}
+	if detail.AggregatedDifficulty != *detail.ComputedDifficulty {
+		t.Fatalf("expected the computed difficulty to replace the suggestion, got %d", detail.AggregatedDifficulty)
+	}
+
+	// Once the columns exist, later migrations leave unrated puzzles alone.
+	unrated := Puzzle{Givens: classicGivens, CreatorSuggestedDifficulty: 5, Published: true}
+	if err := db.Create(&unrated).Error; err != nil {
+		t.Fatalf("insert puzzle: %v", err)
+	}
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	var reloaded Puzzle
+	if err := db.First(&reloaded, unrated.ID).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if reloaded.ComputedDifficulty != nil || reloaded.SERating != nil {
+		t.Fatalf("expected no backfill once the columns exist, got %+v", reloaded)
+	}
+}
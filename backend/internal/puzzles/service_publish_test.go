@@ -0,0 +1,308 @@
+package puzzles
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"sudoku/backend/internal/solver"
+)
+
+func TestPublish_StoresComputedDifficulty(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	creatorID := uint(11)
+	created, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{
+		Givens:                     classicGivens,
+		CreatorSuggestedDifficulty: 9,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	detail, err := svc.Publish(context.Background(), created.ID, creatorID)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if detail.ComputedDifficulty == nil {
+		t.Fatalf("expected computed difficulty to be set")
+	}
+	if *detail.ComputedDifficulty < 1 || *detail.ComputedDifficulty > 3 {
+		t.Fatalf("expected an easy rating, got %d", *detail.ComputedDifficulty)
+	}
+	if detail.AggregatedDifficulty != *detail.ComputedDifficulty {
+		t.Fatalf("expected aggregated difficulty to fall back to the computed rating, got %d", detail.AggregatedDifficulty)
+	}
+}
+
+func TestPublish_NotUniqueIncludesAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	creatorID := uint(12)
+	created, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Givens: ambiguousGivens})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	_, err = svc.Publish(context.Background(), created.ID, creatorID)
+	var notUnique *NotUniqueError
+	if !errors.As(err, &notUnique) {
+		t.Fatalf("expected NotUniqueError, got %v", err)
+	}
+	if err.Error() != "puzzle_must_have_unique_solution" {
+		t.Fatalf("unexpected error message %q", err.Error())
+	}
+	if notUnique.Ambiguity == nil || len(notUnique.Ambiguity.DiffCells) == 0 {
+		t.Fatalf("expected an ambiguity report, got %+v", notUnique.Ambiguity)
+	}
+}
+
+func TestPublish_KillerCagesResolveUniqueness(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	// The two cages put back the givens missing from ambiguousGivens.
+	cages := []solver.Cage{{Cells: []int{0}, Sum: 5}, {Cells: []int{1}, Sum: 3}}
+
+	validated, err := svc.Validate(context.Background(), ValidateRequest{Givens: ambiguousGivens, Cages: cages, IncludeSolution: true})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !validated.Unique || validated.Solution == "" || validated.Steps != nil {
+		t.Fatalf("expected a unique killer puzzle without a classic solve path, got %+v", validated)
+	}
+
+	creatorID := uint(13)
+	created, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Givens: ambiguousGivens, Cages: cages})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	detail, err := svc.Publish(context.Background(), created.ID, creatorID)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(detail.Cages) != 2 || detail.Cages[0].Sum != 5 {
+		t.Fatalf("expected cages to round-trip, got %+v", detail.Cages)
+	}
+	if detail.ComputedDifficulty != nil {
+		t.Fatalf("expected no classic rating for a killer puzzle")
+	}
+
+	if _, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{
+		Givens: ambiguousGivens,
+		Cages:  []solver.Cage{{Cells: []int{0, 1}, Sum: 30}},
+	}); err == nil || err.Error() != "invalid_cage_sum" {
+		t.Fatalf("expected invalid_cage_sum, got %v", err)
+	}
+}
+
+func TestPublish_HonorsRuleSet(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	// Carve an anti-knight solution down to givens that are only unique under that rule.
+	antiKnight := solver.Variant{Rules: []string{solver.RuleAntiKnight}}
+	solutions, err := antiKnight.FindSolutions(solver.Grid{}, 1)
+	if err != nil || len(solutions) != 1 {
+		t.Fatalf("expected an anti-knight solution, got %v", err)
+	}
+	grid := solutions[0]
+	for i := 0; i < 81; i++ {
+		if n, _ := solver.CountSolutions(grid, 2); n > 1 {
+			break
+		}
+		candidate := grid
+		candidate[i] = 0
+		if n, _ := antiKnight.CountSolutions(candidate, 2); n == 1 {
+			grid = candidate
+		}
+	}
+	if n, _ := solver.CountSolutions(grid, 2); n < 2 {
+		t.Fatalf("expected the carved puzzle to be ambiguous under classic rules")
+	}
+
+	creatorID := uint(14)
+	classic, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Givens: grid.String()})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Publish(context.Background(), classic.ID, creatorID); err == nil {
+		t.Fatalf("expected classic publish to fail")
+	}
+
+	created, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{
+		Givens: grid.String(),
+		Rules:  []string{solver.RuleAntiKnight},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	detail, err := svc.Publish(context.Background(), created.ID, creatorID)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(detail.Rules) != 1 || detail.Rules[0] != solver.RuleAntiKnight {
+		t.Fatalf("expected the rule set to round-trip, got %v", detail.Rules)
+	}
+
+	if _, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Rules: []string{"sandwich"}}); err == nil {
+		t.Fatalf("expected unknown rules to be rejected")
+	}
+}
+
+func TestPublish_JigsawRegions(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	// Boxes 1 and 2 trade R3C3 and R1C4; a classic solution with the same digit in both
+	// cells is also a jigsaw solution.
+	regions := "111122333111222333112222333" + strings.Repeat("444555666", 3) + strings.Repeat("777888999", 3)
+	var seed solver.Grid
+	seed[3], seed[20] = 1, 1
+	solutions, err := solver.FindSolutions(seed, 1)
+	if err != nil || len(solutions) != 1 {
+		t.Fatalf("expected a solution, got %v", err)
+	}
+	jigsaw := solver.Variant{Regions: regions}
+	grid := solutions[0]
+	for i := 0; i < 81; i += 2 {
+		candidate := grid
+		candidate[i] = 0
+		if n, _ := jigsaw.CountSolutions(candidate, 2); n == 1 {
+			grid = candidate
+		}
+	}
+
+	creatorID := uint(15)
+	created, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Givens: grid.String(), Regions: regions})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	detail, err := svc.Publish(context.Background(), created.ID, creatorID)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if detail.Regions != regions {
+		t.Fatalf("expected regions to round-trip, got %q", detail.Regions)
+	}
+
+	hint, err := svc.Hint(context.Background(), created.ID, nil, HintRequest{Values: grid.String(), Level: 1})
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if hint.Available || hint.Reason != "variant_not_supported" {
+		t.Fatalf("expected hints to be unavailable for jigsaw, got %+v", hint)
+	}
+
+	if _, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Regions: strings.Repeat("1", 81)}); err == nil {
+		t.Fatalf("expected an invalid region map to be rejected")
+	}
+}
+
+func TestPublish_GeometryResolvesAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+
+	// The two classic solutions start 345 and 534; a thermometer over R1C1-R1C2 keeps
+	// only the first.
+	geometry := solver.Geometry{Thermometers: [][]int{{0, 1}}}
+	creatorID := uint(16)
+	created, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Givens: ambiguousGivens, Geometry: geometry})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	detail, err := svc.Publish(context.Background(), created.ID, creatorID)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if detail.Geometry == nil || len(detail.Geometry.Thermometers) != 1 {
+		t.Fatalf("expected the geometry to round-trip, got %+v", detail.Geometry)
+	}
+	if detail.ComputedDifficulty != nil {
+		t.Fatalf("expected no technique rating for a geometry puzzle")
+	}
+
+	bad := solver.Geometry{Arrows: []solver.Arrow{{Circle: 0, Path: []int{40}}}}
+	if _, err := svc.Create(context.Background(), creatorID, CreatePuzzleRequest{Geometry: bad}); err == nil || err.Error() != "geometry_line_not_connected" {
+		t.Fatalf("expected a broken arrow to be rejected, got %v", err)
+	}
+}
+
+func TestPublish_RejectsEquivalentPuzzle(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+	creatorID := uint(17)
+	ctx := context.Background()
+
+	// A published puzzle, and the same puzzle rotated by 90 degrees with 1 and 2 swapped.
+	const givens = "000000010400000000020000000000050407008000300001090000300400200050100000000806000"
+	var rotated [81]byte
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			ch := givens[r*9+c]
+			switch ch {
+			case '1':
+				ch = '2'
+			case '2':
+				ch = '1'
+			}
+			rotated[c*9+8-r] = ch
+		}
+	}
+
+	original, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: givens})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Publish(ctx, original.ID, creatorID); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	copied, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: string(rotated[:])})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, err = svc.Publish(ctx, copied.ID, creatorID)
+	var duplicate *DuplicateError
+	if !errors.As(err, &duplicate) || duplicate.PuzzleID != original.ID {
+		t.Fatalf("expected a duplicate of %d, got %v", original.ID, err)
+	}
+}
+
+func TestPublishedFingerprintsAreUnique(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	fingerprint := strings.Repeat("e", 64)
+	insert := func(published bool) error {
+		p := Puzzle{Givens: classicGivens, CreatorSuggestedDifficulty: 3, Fingerprint: &fingerprint, Published: published}
+		return db.Create(&p).Error
+	}
+
+	if err := insert(true); err != nil {
+		t.Fatalf("insert published: %v", err)
+	}
+	if err := insert(false); err != nil {
+		t.Fatalf("expected a draft to share the fingerprint, got %v", err)
+	}
+	if err := insert(true); err == nil {
+		t.Fatalf("expected a second published puzzle with the fingerprint to be rejected")
+	}
+}
@@ -0,0 +1,114 @@
+package puzzles
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// kidsGivens is a unique 4x4 puzzle whose solution is 1234/3412/2143/4321.
+const kidsGivens = "0000001201030320"
+
+func TestSizes_FourByFourDraftPublishAndProgress(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	svc := NewService(db)
+	ctx := context.Background()
+
+	creatorID := uint(41)
+	created, err := svc.Create(ctx, creatorID, CreatePuzzleRequest{Givens: "0000 0012 0103 03", Size: 4})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	detail, err := svc.Get(ctx, created.ID, &creatorID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if detail.Size != 4 || detail.Givens != "0000001201030300" {
+		t.Fatalf("expected a padded 4x4 draft, got size %d givens %s", detail.Size, detail.Givens)
+	}
+
+	var notUnique *NotUniqueError
+	if _, err := svc.Publish(ctx, created.ID, creatorID); !errors.As(err, &notUnique) {
+		t.Fatalf("expected the incomplete draft to be ambiguous, got %v", err)
+	}
+
+	if _, err := svc.Update(ctx, created.ID, creatorID, UpdatePuzzleRequest{Givens: kidsGivens}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	detail, err = svc.Publish(ctx, created.ID, creatorID)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if detail.Size != 4 || detail.ComputedDifficulty != nil {
+		t.Fatalf("expected an unrated 4x4 puzzle, got %+v", detail)
+	}
+
+	progress, err := svc.SaveProgress(ctx, created.ID, creatorID, SaveProgressRequest{
+		Values:      "1234001201030320",
+		CornerNotes: make([]int, 16),
+		CenterNotes: append([]int{0, 0, 0, 0, 0b1100}, make([]int, 11)...),
+	})
+	if err != nil {
+		t.Fatalf("save progress: %v", err)
+	}
+	if progress.Progress.Filled != 4 || progress.Progress.Total != 10 {
+		t.Fatalf("unexpected progress %+v", progress.Progress)
+	}
+	if _, err := svc.SaveProgress(ctx, created.ID, creatorID, SaveProgressRequest{
+		Values:      kidsGivens,
+		CornerNotes: make([]int, 16),
+		CenterNotes: append([]int{0b10000}, make([]int, 15)...),
+	}); err == nil || err.Error() != "invalid_notes" {
+		t.Fatalf("expected a note for digit 5 to be rejected, got %v", err)
+	}
+
+	hint, err := svc.Hint(ctx, created.ID, &creatorID, HintRequest{Values: kidsGivens})
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if hint.Available || hint.Reason != "variant_not_supported" {
+		t.Fatalf("expected hints to be unavailable for 4x4, got %+v", hint)
+	}
+}
+
+func TestSizes_ValidateHexadoku(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+	ctx := context.Background()
+
+	// The first row holds every hex digit, so only the rest of the grid is open.
+	givens := "0123456789abcdef" + strings.Repeat(".", 240)
+	resp, err := svc.Validate(ctx, ValidateRequest{Givens: givens, Size: 16})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !resp.Valid || !resp.Solvable || resp.Unique || resp.SolutionCount != 2 {
+		t.Fatalf("expected a valid ambiguous grid, got %+v", resp)
+	}
+	if !strings.HasPrefix(resp.Normalized, "0123456789ABCDEF.") {
+		t.Fatalf("expected upper-case hex givens, got %s", resp.Normalized[:17])
+	}
+
+	tests := []struct {
+		req  ValidateRequest
+		want string
+	}{
+		{ValidateRequest{Givens: givens, Size: 10}, "invalid_size"},
+		{ValidateRequest{Givens: "00" + givens, Size: 16}, "givens_must_be_256_chars"},
+		{ValidateRequest{Givens: givens, Size: 16, Rules: []string{"diagonal"}}, "variant_requires_9x9"},
+		{ValidateRequest{Givens: "00" + givens[2:], Size: 16}, "row_conflict"},
+	}
+	for _, tt := range tests {
+		resp, err := svc.Validate(ctx, tt.req)
+		if err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		if resp.Valid || len(resp.Errors) != 1 || resp.Errors[0] != tt.want {
+			t.Fatalf("expected %s, got %+v", tt.want, resp)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package puzzles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"sudoku/backend/internal/solver"
+)
+
+func TestValidate_IncludesSolutionAndSteps(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+
+	plain, err := svc.Validate(context.Background(), ValidateRequest{Givens: classicGivens})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if plain.Solution != "" || plain.Steps != nil {
+		t.Fatalf("expected no solution without opting in, got %+v", plain)
+	}
+
+	resp, err := svc.Validate(context.Background(), ValidateRequest{Givens: classicGivens, IncludeSolution: true})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !resp.Unique || len(resp.Solution) != 81 {
+		t.Fatalf("expected the unique solution, got %+v", resp)
+	}
+	if resp.LogicallySolved == nil || !*resp.LogicallySolved || len(resp.Steps) == 0 {
+		t.Fatalf("expected a logical solve path, got %+v", resp)
+	}
+
+	_, grid, err := solver.ParseAndNormalize(classicGivens)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, step := range resp.Steps {
+		for _, p := range step.Placements {
+			grid[p.Cell] = p.Digit
+		}
+	}
+	if grid.String() != resp.Solution {
+		t.Fatalf("steps do not reach the solution:\n%s\n%s", grid.String(), resp.Solution)
+	}
+}
+
+func TestValidate_ReportsRedundantGivens(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+
+	plain, err := svc.Validate(context.Background(), ValidateRequest{Givens: classicGivens})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if plain.Minimal != nil || plain.RedundantGivens != nil {
+		t.Fatalf("expected no minimality report unless asked for, got %+v", plain)
+	}
+
+	resp, err := svc.Validate(context.Background(), ValidateRequest{Givens: classicGivens, IncludeRedundant: true})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if resp.Minimal == nil || *resp.Minimal || len(resp.RedundantGivens) == 0 {
+		t.Fatalf("expected redundant givens, got %+v", resp)
+	}
+	for _, c := range resp.RedundantGivens {
+		if classicGivens[c.Row*9+c.Col] == '0' {
+			t.Fatalf("cell %+v is not a given", c)
+		}
+	}
+
+	ambiguous, err := svc.Validate(context.Background(), ValidateRequest{Givens: ambiguousGivens, IncludeRedundant: true})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if ambiguous.Minimal != nil || ambiguous.RedundantGivens != nil {
+		t.Fatalf("expected no minimality report for a non-unique puzzle, got %+v", ambiguous)
+	}
+}
+
+// ambiguousGivens is classicGivens without its first two givens, which is no longer unique.
+const ambiguousGivens = "000070000600195000098000060800060003400803001700020006060000280000419005000080079"
+
+func TestValidate_ReportsAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+
+	resp, err := svc.Validate(context.Background(), ValidateRequest{Givens: ambiguousGivens})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if resp.Unique || resp.Ambiguity == nil {
+		t.Fatalf("expected an ambiguity report, got %+v", resp)
+	}
+	a := resp.Ambiguity
+	if a.Solutions[0] == a.Solutions[1] || len(a.DiffCells) == 0 {
+		t.Fatalf("expected two differing solutions, got %+v", a)
+	}
+	if !a.Resolves || a.SuggestedDigit < 1 || a.SuggestedDigit > 9 {
+		t.Fatalf("expected a resolving suggestion, got %+v", a)
+	}
+}
+
+func TestValidate_CancelledContextTimesOut(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(newTestDB(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	empty := strings.Repeat("0", 81)
+	if _, err := svc.Validate(ctx, ValidateRequest{Givens: empty}); !errors.Is(err, solver.ErrTimeout) {
+		t.Fatalf("expected solver.ErrTimeout, got %v", err)
+	}
+	if status := httpStatusFromError(solver.ErrTimeout); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for a solve timeout, got %d", status)
+	}
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgCanvas collects SVG elements.
+type svgCanvas struct {
+	width, height float64
+	body          strings.Builder
+}
+
+func newSVGCanvas(width, height float64) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (s *svgCanvas) rect(x, y, w, h float64, c color.RGBA) {
+	fmt.Fprintf(&s.body, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+		num(x), num(y), num(w), num(h), hex(c))
+}
+
+func (s *svgCanvas) stroke(points []point, width float64, c color.RGBA) {
+	s.body.WriteString(`<polyline points="`)
+	for i, p := range points {
+		if i > 0 {
+			s.body.WriteByte(' ')
+		}
+		s.body.WriteString(num(p.x) + "," + num(p.y))
+	}
+	fmt.Fprintf(&s.body, `" fill="none" stroke="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"/>`+"\n",
+		hex(c), num(width))
+}
+
+func (s *svgCanvas) writeTo(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(s.width), num(s.height), num(s.width), num(s.height))
+	bw.WriteString(s.body.String())
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// num formats a coordinate with at most two decimals.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// rasterCanvas draws into an RGBA image, anti-aliasing strokes by the distance from
+// each pixel centre to the polyline.
+type rasterCanvas struct {
+	img *image.RGBA
+}
+
+func newRasterCanvas(width, height int) *rasterCanvas {
+	return &rasterCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (r *rasterCanvas) rect(x, y, w, h float64, c color.RGBA) {
+	b := r.img.Bounds()
+	for py := max(int(y), b.Min.Y); py < min(int(math.Ceil(y+h)), b.Max.Y); py++ {
+		for px := max(int(x), b.Min.X); px < min(int(math.Ceil(x+w)), b.Max.X); px++ {
+			r.img.SetRGBA(px, py, c)
+		}
+	}
+}
+
+func (r *rasterCanvas) stroke(points []point, width float64, c color.RGBA) {
+	if len(points) == 0 {
+		return
+	}
+	half := width / 2
+	minX, minY, maxX, maxY := points[0].x, points[0].y, points[0].x, points[0].y
+	for _, p := range points[1:] {
+		minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+		minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+	}
+	b := r.img.Bounds()
+	x0 := max(int(math.Floor(minX-half-1)), b.Min.X)
+	x1 := min(int(math.Ceil(maxX+half+1)), b.Max.X)
+	y0 := max(int(math.Floor(minY-half-1)), b.Min.Y)
+	y1 := min(int(math.Ceil(maxY+half+1)), b.Max.Y)
+
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			p := point{float64(px) + 0.5, float64(py) + 0.5}
+			d := math.Inf(1)
+			for i := range points {
+				a, e := points[i], points[i]
+				if i+1 < len(points) {
+					e = points[i+1]
+				}
+				d = math.Min(d, segmentDistance(p, a, e))
+			}
+			if coverage := math.Min(1, half+0.5-d); coverage > 0 {
+				r.blend(px, py, c, coverage)
+			}
+		}
+	}
+}
+
+// blend paints c over the pixel with the given opacity.
+func (r *rasterCanvas) blend(x, y int, c color.RGBA, alpha float64) {
+	dst := r.img.RGBAAt(x, y)
+	mix := func(d, s uint8) uint8 {
+		return uint8(math.Round(float64(d)*(1-alpha) + float64(s)*alpha))
+	}
+	r.img.SetRGBA(x, y, color.RGBA{mix(dst.R, c.R), mix(dst.G, c.G), mix(dst.B, c.B), 0xff})
+}
+
+func (r *rasterCanvas) writeTo(w io.Writer) error {
+	return png.Encode(w, r.img)
+}
+
+// segmentDistance returns the distance from p to the segment a-b.
+func segmentDistance(p, a, b point) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((p.x-a.x)*dx+(p.y-a.y)*dy)/l))
+	}
+	return math.Hypot(p.x-(a.x+t*dx), p.y-(a.y+t*dy))
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"image/color"
+	"math"
+)
+
+// glyphWidth is the width of every glyph; glyphs are one unit high, y pointing down.
+const glyphWidth = 0.6
+
+// glyphs are single-line stroke shapes for the cell characters, so that SVG and PNG
+// draw the same figures without depending on installed fonts.
+var glyphs = map[byte][][]point{
+	'0': {arc(0.3, 0.5, 0.3, 0.5, 0, 360)},
+	'1': {{{0.12, 0.2}, {0.34, 0}, {0.34, 1}}},
+	'2': {join(arc(0.3, 0.27, 0.27, 0.27, 200, 380), []point{{0.02, 1}, {0.6, 1}})},
+	'3': {join(arc(0.3, 0.25, 0.26, 0.25, 210, 450), arc(0.3, 0.74, 0.29, 0.26, 270, 510))},
+	'4': {{{0.45, 1}, {0.45, 0}, {0, 0.68}, {0.6, 0.68}}},
+	'5': {{{0.56, 0}, {0.1, 0}, {0.06, 0.46}}, arc(0.3, 0.68, 0.3, 0.32, 225, 500)},
+	'6': six,
+	'7': {{{0, 0}, {0.6, 0}, {0.22, 1}}},
+	'8': {arc(0.3, 0.25, 0.25, 0.25, 0, 360), arc(0.3, 0.74, 0.29, 0.26, 0, 360)},
+	'9': rotate(six),
+	'A': {{{0, 1}, {0.3, 0}, {0.6, 1}}, {{0.1, 0.66}, {0.5, 0.66}}},
+	'B': {
+		{{0, 0}, {0, 1}},
+		join([]point{{0, 0}, {0.36, 0}}, arc(0.36, 0.24, 0.2, 0.24, 270, 450), []point{{0, 0.48}}),
+		join([]point{{0, 0.48}, {0.38, 0.48}}, arc(0.38, 0.74, 0.22, 0.26, 270, 450), []point{{0, 1}}),
+	},
+	'C': {arc(0.33, 0.5, 0.3, 0.5, 320, 40)},
+	'D': {{{0, 0}, {0, 1}}, join([]point{{0, 0}, {0.22, 0}}, arc(0.22, 0.5, 0.38, 0.5, 270, 450), []point{{0, 1}})},
+	'E': {{{0.6, 0}, {0, 0}, {0, 1}, {0.6, 1}}, {{0, 0.5}, {0.45, 0.5}}},
+	'F': {{{0.6, 0}, {0, 0}, {0, 1}}, {{0, 0.5}, {0.45, 0.5}}},
+}
+
+var six = [][]point{arc(0.3, 0.7, 0.3, 0.3, 0, 360), arc(0.6, 0.7, 0.6, 0.7, 258, 180)}
+
+// arc returns points on an ellipse from angle a0 to a1 in degrees, clockwise on screen
+// when a1 > a0 and counter-clockwise otherwise.
+func arc(cx, cy, rx, ry, a0, a1 float64) []point {
+	steps := int(math.Ceil(math.Abs(a1-a0) / 12))
+	points := make([]point, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		a := (a0 + (a1-a0)*float64(i)/float64(steps)) * math.Pi / 180
+		points = append(points, point{cx + rx*math.Cos(a), cy + ry*math.Sin(a)})
+	}
+	return points
+}
+
+func join(parts ...[]point) []point {
+	var out []point
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// rotate turns strokes half a turn within the glyph box.
+func rotate(strokes [][]point) [][]point {
+	out := make([][]point, len(strokes))
+	for i, s := range strokes {
+		out[i] = make([]point, len(s))
+		for j, p := range s {
+			out[i][j] = point{glyphWidth - p.x, 1 - p.y}
+		}
+	}
+	return out
+}
+
+// drawGlyph draws ch centred on (cx, cy), h pixels high.
+func drawGlyph(c canvas, ch byte, cx, cy, h float64, ink color.RGBA) {
+	x0 := cx - glyphWidth*h/2
+	y0 := cy - h/2
+	for _, s := range glyphs[ch] {
+		points := make([]point, len(s))
+		for i, p := range s {
+			points[i] = point{x0 + p.x*h, y0 + p.y*h}
+		}
+		c.stroke(points, h*0.1, ink)
+	}
+}
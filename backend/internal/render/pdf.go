@@ -0,0 +1,301 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BookletPuzzle is one puzzle of a PDF booklet. Its Solution, when set, goes in the
+// answer key; Values and Notes are not printed.
+type BookletPuzzle struct {
+	Puzzle
+	Title string
+	// Caption is a second, smaller line under the title, e.g. the difficulty.
+	Caption string
+}
+
+// BookletOptions controls the booklet layout.
+type BookletOptions struct {
+	// Title heads every puzzle page.
+	Title string
+	// PerPage is the number of puzzles per page: 1, 2, 4 (default) or 6.
+	PerPage int
+	// Paper is "a4" (default) or "letter".
+	Paper string
+}
+
+// Paper sizes in points.
+var papers = map[string][2]float64{
+	"a4":     {595.28, 841.89},
+	"letter": {612, 792},
+}
+
+// pageGrids maps puzzles per page to columns and rows of puzzles.
+var pageGrids = map[int][2]int{1: {1, 1}, 2: {1, 2}, 4: {2, 2}, 6: {2, 3}}
+
+// answerGrid is the columns and rows of solutions on an answer key page.
+var answerGrid = [2]int{3, 4}
+
+const (
+	pageMargin   = 40.0
+	headerHeight = 36.0
+	footerHeight = 24.0
+	captionSize  = 11.0
+	subtitleSize = 8.5
+	slotPadding  = 12.0
+)
+
+var inkText = color.RGBA{0x11, 0x18, 0x27, 0xff}
+
+// PDF writes a booklet with the puzzles numbered in order, followed by an answer key of
+// those that have a Solution. It needs no fonts beyond the standard PDF ones; digits
+// are drawn as strokes like in SVG and PNG.
+func PDF(w io.Writer, puzzles []BookletPuzzle, opts BookletOptions) error {
+	if len(puzzles) == 0 {
+		return errors.New("no_puzzles")
+	}
+	if opts.PerPage == 0 {
+		opts.PerPage = 4
+	}
+	grid, ok := pageGrids[opts.PerPage]
+	if !ok {
+		return errors.New("invalid_per_page")
+	}
+	if opts.Paper == "" {
+		opts.Paper = "a4"
+	}
+	paper, ok := papers[opts.Paper]
+	if !ok {
+		return errors.New("unknown_paper")
+	}
+
+	doc := &pdfDocument{width: paper[0], height: paper[1]}
+	var answers []int
+	for i := 0; i < len(puzzles); i += opts.PerPage {
+		page := doc.newPage(opts.Title)
+		for j := i; j < len(puzzles) && j < i+opts.PerPage; j++ {
+			p := puzzles[j]
+			if p.Solution != nil {
+				answers = append(answers, j)
+			}
+			caption := strconv.Itoa(j+1) + ". " + p.Title
+			puzzle := Puzzle{Size: p.Size, Givens: p.Givens, Regions: p.Regions}
+			if err := page.slot(grid, j-i, caption, p.Caption, puzzle); err != nil {
+				return err
+			}
+		}
+	}
+
+	perAnswerPage := answerGrid[0] * answerGrid[1]
+	for i := 0; i < len(answers); i += perAnswerPage {
+		page := doc.newPage("Answers")
+		for j := i; j < len(answers) && j < i+perAnswerPage; j++ {
+			p := puzzles[answers[j]]
+			caption := strconv.Itoa(answers[j]+1) + ". " + p.Title
+			puzzle := Puzzle{Size: p.Size, Givens: p.Givens, Solution: p.Solution, Regions: p.Regions}
+			if err := page.slot(answerGrid, j-i, caption, "", puzzle); err != nil {
+				return err
+			}
+		}
+	}
+	return doc.writeTo(w)
+}
+
+// pdfDocument collects pages and writes them as a PDF 1.4 file.
+type pdfDocument struct {
+	width, height float64
+	pages         []*pdfPage
+}
+
+// pdfPage holds a page's content stream. Its methods take coordinates from the top left
+// of the page, like the other canvases.
+type pdfPage struct {
+	doc     *pdfDocument
+	content strings.Builder
+}
+
+// newPage starts a page with a heading and its page number in the footer.
+func (d *pdfDocument) newPage(heading string) *pdfPage {
+	p := &pdfPage{doc: d}
+	d.pages = append(d.pages, p)
+	if heading != "" {
+		p.text(pageMargin, pageMargin+16, 16, true, heading)
+	}
+	number := strconv.Itoa(len(d.pages))
+	// Helvetica's digits are all 0.556 em wide.
+	width := 0.556 * 9 * float64(len(number))
+	p.text((d.width-width)/2, d.height-pageMargin/2-footerHeight/2, 9, false, number)
+	return p
+}
+
+// slot draws a captioned puzzle in cell index of a columns x rows arrangement.
+func (p *pdfPage) slot(grid [2]int, index int, caption, subtitle string, puzzle Puzzle) error {
+	areaW := p.doc.width - 2*pageMargin
+	areaH := p.doc.height - 2*pageMargin - headerHeight - footerHeight
+	slotW := areaW / float64(grid[0])
+	slotH := areaH / float64(grid[1])
+	x := pageMargin + float64(index%grid[0])*slotW
+	y := pageMargin + headerHeight + float64(index/grid[0])*slotH
+
+	textH := captionSize + 4
+	if subtitle != "" {
+		textH += subtitleSize + 4
+	}
+	side := math.Min(slotW, slotH-textH) - slotPadding
+	cell := side / float64(puzzle.Size)
+	l, err := newLayout(puzzle, cell, 0)
+	if err != nil {
+		return err
+	}
+
+	left := x + (slotW-side)/2
+	p.text(left, y+captionSize, captionSize, true, fit(caption, side, captionSize))
+	if subtitle != "" {
+		p.text(left, y+captionSize+4+subtitleSize, subtitleSize, false, fit(subtitle, side, subtitleSize))
+	}
+	l.draw(&pdfCanvas{page: p, x: left, y: y + textH}, puzzle)
+	return nil
+}
+
+// text writes s in Helvetica with its baseline at y.
+func (p *pdfPage) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "%s rg BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		pdfColor(inkText), font, num(size), num(x), num(p.doc.height-y), pdfText(s))
+}
+
+// pdfCanvas draws into a page with its origin at (x, y).
+type pdfCanvas struct {
+	page *pdfPage
+	x, y float64
+}
+
+func (c *pdfCanvas) rect(x, y, w, h float64, col color.RGBA) {
+	fmt.Fprintf(&c.page.content, "%s rg %s %s %s %s re f\n",
+		pdfColor(col), num(c.x+x), num(c.page.doc.height-c.y-y-h), num(w), num(h))
+}
+
+func (c *pdfCanvas) stroke(points []point, width float64, col color.RGBA) {
+	if len(points) == 0 {
+		return
+	}
+	b := &c.page.content
+	fmt.Fprintf(b, "%s RG %s w 1 J 1 j", pdfColor(col), num(width))
+	for i, pt := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(b, " %s %s %s", num(c.x+pt.x), num(c.page.doc.height-c.y-pt.y), op)
+	}
+	if len(points) == 1 {
+		// A lone point still gets a round dot.
+		fmt.Fprintf(b, " %s %s l", num(c.x+points[0].x), num(c.page.doc.height-c.y-points[0].y))
+	}
+	b.WriteString(" S\n")
+}
+
+// writeTo writes the catalog, the page tree, the two fonts and each page with its
+// compressed content stream, followed by the cross-reference table.
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	const firstPage = 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(d.width), num(d.height), firstPage+2*i+1))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write([]byte(page.content.String())); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(out.Bytes()); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// fit shortens s with an ellipsis so that it roughly fits width points at size, going
+// by Helvetica's average character width.
+func fit(s string, width, size float64) string {
+	max := int(width / (0.6 * size))
+	runes := []rune(s)
+	if len(runes) <= max || max < 1 {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+func pdfColor(c color.RGBA) string {
+	return fmt.Sprintf("%s %s %s", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding has.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfText encodes s as the body of a PDF string in WinAnsiEncoding, replacing
+// characters the standard fonts lack with '?'.
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
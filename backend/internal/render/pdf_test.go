@@ -0,0 +1,95 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFBooklet(t *testing.T) {
+	t.Parallel()
+
+	solution := make([]uint8, 81)
+	for i := range solution {
+		solution[i] = uint8(i%9 + 1)
+	}
+	var puzzles []BookletPuzzle
+	for i := 0; i < 5; i++ {
+		p := BookletPuzzle{Puzzle: classicPuzzle(), Title: "Weekly (" + strconv.Itoa(i) + ")", Caption: "Difficulty 3"}
+		if i != 2 {
+			p.Solution = solution
+		}
+		puzzles = append(puzzles, p)
+	}
+
+	var buf bytes.Buffer
+	if err := PDF(&buf, puzzles, BookletOptions{Title: "Club packet – week 7"}); err != nil {
+		t.Fatalf("pdf: %v", err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("expected a PDF header and trailer")
+	}
+	// Two puzzle pages of four and one answer page.
+	if n := bytes.Count(out, []byte("/Type /Page ")); n != 3 {
+		t.Fatalf("expected 3 pages, got %d", n)
+	}
+
+	start, err := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)[1]))
+	if err != nil || !bytes.HasPrefix(out[start:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[start:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Fatalf("xref entry %d points at %.20q", i+1, out[off:])
+		}
+	}
+
+	var text strings.Builder
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(out, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		content, _ := io.ReadAll(zr)
+		text.Write(content)
+	}
+	for _, want := range []string{`(Club packet \226 week 7)`, `(5. Weekly \(4\))`, `(Answers)`, `(Difficulty 3)`, " re f", " S\n"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("expected %q in the page contents", want)
+		}
+	}
+	// Five puzzles, then four answers: the third puzzle has no solution.
+	if n := strings.Count(text.String(), "Weekly"); n != 9 {
+		t.Errorf("expected 9 captions, got %d", n)
+	}
+	if n := strings.Count(text.String(), "3. Weekly"); n != 1 {
+		t.Error("expected the puzzle without a solution to be left out of the answer key")
+	}
+}
+
+func TestPDFRejectsInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	puzzles := []BookletPuzzle{{Puzzle: classicPuzzle()}}
+	cases := map[string]struct {
+		puzzles []BookletPuzzle
+		opts    BookletOptions
+		want    string
+	}{
+		"empty":    {nil, BookletOptions{}, "no_puzzles"},
+		"per page": {puzzles, BookletOptions{PerPage: 3}, "invalid_per_page"},
+		"paper":    {puzzles, BookletOptions{Paper: "a5"}, "unknown_paper"},
+	}
+	for name, tc := range cases {
+		if err := PDF(io.Discard, tc.puzzles, tc.opts); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: expected %s, got %v", name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Package render draws puzzles as SVG and PNG images for previews, emails and print.
+package render
+
+import (
+	"errors"
+	"image/color"
+	"io"
+	"math"
+
+	"sudoku/backend/internal/solver"
+)
+
+// Puzzle is what the renderer draws. Cell slices hold Size*Size values in row order.
+type Puzzle struct {
+	Size   int
+	Givens []uint8
+	// Values are the player's digits, drawn in blue. Optional.
+	Values []uint8
+	// Notes are the player's candidates as bitmasks, bit d-1 for digit d, drawn small in
+	// cells without a digit. Optional.
+	Notes []uint16
+	// Solution fills the cells that are still empty, in grey. Optional.
+	Solution []uint8
+	// Regions is a normalized jigsaw map (see solver.NormalizeRegions) whose borders
+	// replace the 3x3 boxes. Optional, 9x9 only.
+	Regions string
+}
+
+// Options controls the image size.
+type Options struct {
+	// CellSize is the width of a cell in pixels (default 48).
+	CellSize int
+}
+
+// DefaultCellSize is the cell width used when Options.CellSize is zero.
+const DefaultCellSize = 48
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	inkGiven   = color.RGBA{0x11, 0x18, 0x27, 0xff}
+	inkValue   = color.RGBA{0x25, 0x63, 0xeb, 0xff}
+	inkAnswer  = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	inkNote    = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	lineThin   = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	lineThick  = color.RGBA{0x11, 0x18, 0x27, 0xff}
+)
+
+// SVG writes the puzzle as an SVG document.
+func SVG(w io.Writer, p Puzzle, opts Options) error {
+	l, err := opts.layout(p)
+	if err != nil {
+		return err
+	}
+	c := newSVGCanvas(l.width, l.height)
+	l.draw(c, p)
+	return c.writeTo(w)
+}
+
+// PNG writes the puzzle as a PNG image.
+func PNG(w io.Writer, p Puzzle, opts Options) error {
+	l, err := opts.layout(p)
+	if err != nil {
+		return err
+	}
+	c := newRasterCanvas(int(math.Ceil(l.width)), int(math.Ceil(l.height)))
+	l.draw(c, p)
+	return c.writeTo(w)
+}
+
+// canvas is a drawing surface; coordinates are pixels (points in PDFs) from the top
+// left.
+type canvas interface {
+	rect(x, y, w, h float64, c color.RGBA)
+	// stroke draws a polyline with round caps and joins.
+	stroke(points []point, width float64, c color.RGBA)
+}
+
+type point struct{ x, y float64 }
+
+type layout struct {
+	size          int
+	cell, margin  float64
+	width, height float64
+	region        func(idx int) int
+}
+
+// layout checks the cell size and lays p out with a margin for the outer border.
+func (o Options) layout(p Puzzle) (*layout, error) {
+	cell := o.CellSize
+	if cell == 0 {
+		cell = DefaultCellSize
+	}
+	if cell < 8 || cell > 256 {
+		return nil, errors.New("invalid_cell_size")
+	}
+	return newLayout(p, float64(cell), math.Max(2, float64(cell)/8))
+}
+
+// newLayout checks p and places its grid margin units from the top left.
+func newLayout(p Puzzle, cell, margin float64) (*layout, error) {
+	if !solver.ValidSize(p.Size) {
+		return nil, errors.New("invalid_size")
+	}
+	n := p.Size * p.Size
+	if len(p.Givens) != n ||
+		(p.Values != nil && len(p.Values) != n) ||
+		(p.Notes != nil && len(p.Notes) != n) ||
+		(p.Solution != nil && len(p.Solution) != n) {
+		return nil, errors.New("invalid_cells")
+	}
+
+	l := &layout{size: p.Size, cell: cell, margin: margin}
+	l.width = float64(p.Size)*l.cell + 2*l.margin
+	l.height = l.width
+
+	switch {
+	case p.Regions != "":
+		if p.Size != 9 || len(p.Regions) != 81 {
+			return nil, errors.New("invalid_regions")
+		}
+		l.region = func(idx int) int { return int(p.Regions[idx]) }
+	default:
+		rows, cols := solver.BoxShape(p.Size)
+		l.region = func(idx int) int {
+			r, c := idx/p.Size, idx%p.Size
+			return r/rows*p.Size + c/cols
+		}
+	}
+	return l, nil
+}
+
+func (l *layout) draw(c canvas, p Puzzle) {
+	c.rect(0, 0, l.width, l.height, background)
+	for idx := 0; idx < p.Size*p.Size; idx++ {
+		x := l.margin + float64(idx%p.Size)*l.cell
+		y := l.margin + float64(idx/p.Size)*l.cell
+		switch {
+		case p.Givens[idx] != 0:
+			l.digit(c, x, y, p.Givens[idx], inkGiven)
+		case p.Values != nil && p.Values[idx] != 0:
+			l.digit(c, x, y, p.Values[idx], inkValue)
+		case p.Solution != nil && p.Solution[idx] != 0:
+			l.digit(c, x, y, p.Solution[idx], inkAnswer)
+		case p.Notes != nil && p.Notes[idx] != 0:
+			l.notes(c, x, y, p.Notes[idx])
+		}
+	}
+	l.grid(c)
+}
+
+// digit draws a cell's digit centred in the cell at (x, y).
+func (l *layout) digit(c canvas, x, y float64, v uint8, ink color.RGBA) {
+	h := l.cell * 0.56
+	drawGlyph(c, solver.DigitChar(l.size, v), x+l.cell/2, y+l.cell/2, h, ink)
+}
+
+// notes draws each candidate in its own slot of a small grid inside the cell.
+func (l *layout) notes(c canvas, x, y float64, mask uint16) {
+	cols := int(math.Ceil(math.Sqrt(float64(l.size))))
+	rows := (l.size + cols - 1) / cols
+	slotW := l.cell / float64(cols)
+	slotH := l.cell / float64(rows)
+	h := math.Min(slotW, slotH) * 0.6
+	for d := 1; d <= l.size; d++ {
+		if mask&(1<<(d-1)) == 0 {
+			continue
+		}
+		k := d - 1
+		cx := x + (float64(k%cols)+0.5)*slotW
+		cy := y + (float64(k/cols)+0.5)*slotH
+		drawGlyph(c, solver.DigitChar(l.size, uint8(d)), cx, cy, h, inkNote)
+	}
+}
+
+// grid draws thin cell lines, then thick region borders and the outer frame.
+func (l *layout) grid(c canvas) {
+	n := l.size
+	thin := math.Max(1, l.cell/48)
+	thick := math.Max(2, l.cell/16)
+	end := l.margin + float64(n)*l.cell
+	for k := 1; k < n; k++ {
+		at := l.margin + float64(k)*l.cell
+		c.stroke([]point{{at, l.margin}, {at, end}}, thin, lineThin)
+		c.stroke([]point{{l.margin, at}, {end, at}}, thin, lineThin)
+	}
+
+	for idx := 0; idx < n*n; idx++ {
+		r, col := idx/n, idx%n
+		x := l.margin + float64(col)*l.cell
+		y := l.margin + float64(r)*l.cell
+		if col+1 < n && l.region(idx) != l.region(idx+1) {
+			c.stroke([]point{{x + l.cell, y}, {x + l.cell, y + l.cell}}, thick, lineThick)
+		}
+		if r+1 < n && l.region(idx) != l.region(idx+n) {
+			c.stroke([]point{{x, y + l.cell}, {x + l.cell, y + l.cell}}, thick, lineThick)
+		}
+	}
+	c.stroke([]point{
+		{l.margin, l.margin}, {end, l.margin}, {end, end}, {l.margin, end}, {l.margin, l.margin},
+	}, thick, lineThick)
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+const classic = "530070000600195000098000060800060003400803001700020006060000280000419005000080079"
+
+func classicPuzzle() Puzzle {
+	p := Puzzle{Size: 9, Givens: make([]uint8, 81), Notes: make([]uint16, 81)}
+	for i := range p.Givens {
+		p.Givens[i] = classic[i] - '0'
+	}
+	p.Notes[2] = 0b101
+	return p
+}
+
+func TestSVGIsWellFormed(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := SVG(&buf, classicPuzzle(), Options{}); err != nil {
+		t.Fatalf("svg: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "<svg ") || !strings.Contains(out, `width="444"`) {
+		t.Fatalf("unexpected header: %.120s", out)
+	}
+	dec := xml.NewDecoder(&buf)
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("svg is not well-formed: %v", err)
+		}
+	}
+	// 30 givens and 2 notes, each at least one stroke, plus the grid.
+	if n := strings.Count(out, "<polyline"); n < 32+16+4+1 {
+		t.Fatalf("expected glyphs and grid lines, got %d polylines", n)
+	}
+}
+
+func TestPNGDrawsGridAndDigits(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, classicPuzzle(), Options{CellSize: 32}); err != nil {
+		t.Fatalf("png: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	margin := 4
+	if b := img.Bounds(); b.Dx() != 9*32+2*margin || b.Dy() != b.Dx() {
+		t.Fatalf("unexpected size %v", b)
+	}
+	dark := func(x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return r+g+b < 3*0x4000
+	}
+	if !dark(margin, 100) || !dark(margin+3*32, 100) {
+		t.Fatal("expected thick borders on the frame and between boxes")
+	}
+	if dark(margin+32+16, margin+32+16) {
+		t.Fatal("expected an empty cell to stay blank")
+	}
+	inked := 0
+	for y := margin; y < margin+32; y++ {
+		for x := margin + 4; x < margin+28; x++ {
+			if dark(x, y) {
+				inked++
+			}
+		}
+	}
+	if inked == 0 {
+		t.Fatal("expected the given 5 to be drawn")
+	}
+}
+
+func TestRejectsInvalidPuzzles(t *testing.T) {
+	t.Parallel()
+
+	p := classicPuzzle()
+	cases := map[string]struct {
+		p    Puzzle
+		opts Options
+		want string
+	}{
+		"size":      {Puzzle{Size: 7, Givens: make([]uint8, 49)}, Options{}, "invalid_size"},
+		"cells":     {Puzzle{Size: 9, Givens: make([]uint8, 80)}, Options{}, "invalid_cells"},
+		"values":    {Puzzle{Size: 9, Givens: p.Givens, Values: make([]uint8, 3)}, Options{}, "invalid_cells"},
+		"cell size": {p, Options{CellSize: 1000}, "invalid_cell_size"},
+		"regions":   {Puzzle{Size: 9, Givens: p.Givens, Regions: "1"}, Options{}, "invalid_regions"},
+	}
+	for name, tc := range cases {
+		if err := SVG(io.Discard, tc.p, tc.opts); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: expected %s, got %v", name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package solver
+
+import "context"
+
+// Ambiguity describes why a puzzle is not unique: two of its solutions, the cells where
+// they differ, and a given that would rule out at least one of them.
+type Ambiguity struct {
+	Solutions      [2]Grid
+	DiffCells      []int
+	SuggestedCell  int
+	SuggestedDigit uint8
+	// Resolves is true when adding the suggested given leaves exactly one solution.
+	Resolves bool
+}
+
+// FindAmbiguity returns nil when the puzzle has fewer than two solutions.
+func FindAmbiguity(g Grid) (*Ambiguity, error) {
+	return Variant{}.FindAmbiguity(g)
+}
+
+// ExplainAmbiguity compares two solutions of g. The suggested given is taken from one of
+// the solutions at a differing cell, preferring one that makes the puzzle unique.
+func ExplainAmbiguity(g Grid, a, b Grid) Ambiguity {
+	return Variant{}.ExplainAmbiguity(g, a, b)
+}
+
+// FindAmbiguity is FindAmbiguity under the variant's rules.
+func (v Variant) FindAmbiguity(g Grid) (*Ambiguity, error) {
+	return v.FindAmbiguityContext(context.Background(), g)
+}
+
+// ExplainAmbiguity is ExplainAmbiguity under the variant's rules.
+func (v Variant) ExplainAmbiguity(g Grid, a, b Grid) Ambiguity {
+	out, _ := v.ExplainAmbiguityContext(context.Background(), g, a, b)
+	return out
+}
+
+// FindAmbiguityContext is FindAmbiguity, stopping with ErrTimeout once ctx is done.
+func (v Variant) FindAmbiguityContext(ctx context.Context, g Grid) (*Ambiguity, error) {
+	solutions, err := v.FindSolutionsContext(ctx, g, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(solutions) < 2 {
+		return nil, nil
+	}
+	a, err := v.ExplainAmbiguityContext(ctx, g, solutions[0], solutions[1])
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ExplainAmbiguityContext is ExplainAmbiguity, stopping with ErrTimeout once ctx is done.
+func (v Variant) ExplainAmbiguityContext(ctx context.Context, g Grid, a, b Grid) (Ambiguity, error) {
+	out := Ambiguity{Solutions: [2]Grid{a, b}}
+	for i := 0; i < 81; i++ {
+		if a[i] != b[i] {
+			out.DiffCells = append(out.DiffCells, i)
+		}
+	}
+	if len(out.DiffCells) == 0 {
+		return out, nil
+	}
+
+	out.SuggestedCell = out.DiffCells[0]
+	out.SuggestedDigit = a[out.DiffCells[0]]
+	for _, idx := range out.DiffCells {
+		for _, digit := range [2]uint8{a[idx], b[idx]} {
+			candidate := g
+			candidate[idx] = digit
+			count, err := v.CountSolutionsContext(ctx, candidate, 2)
+			if err != nil {
+				return out, err
+			}
+			if count == 1 {
+				out.SuggestedCell = idx
+				out.SuggestedDigit = digit
+				out.Resolves = true
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,313 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+)
+
+// BitboardSolver implements Solver for classic grids with candidate bitmasks. Before each
+// guess it propagates naked and hidden singles and pointing pairs, and it backtracks
+// with an undo trail instead of copying the grid at every level. It is the classic
+// backend behind CountSolutions.
+type BitboardSolver struct{}
+
+// CountSolutions counts the solutions of a classic Sudoku up to limit.
+func (s BitboardSolver) CountSolutions(g Grid, limit int) (int, error) {
+	return s.countContext(context.Background(), g, limit)
+}
+
+// FindSolutions returns up to limit solutions of a classic Sudoku.
+func (s BitboardSolver) FindSolutions(g Grid, limit int) ([]Grid, error) {
+	return s.findContext(context.Background(), g, limit)
+}
+
+func (BitboardSolver) countContext(ctx context.Context, g Grid, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, errors.New("invalid_limit")
+	}
+	b := bitboard{stop: deadline{ctx: ctx}}
+	if !b.init(g) {
+		return 0, nil
+	}
+	b.limit = limit
+	b.search()
+	return b.count, b.stop.err
+}
+
+func (BitboardSolver) findContext(ctx context.Context, g Grid, limit int) ([]Grid, error) {
+	if limit <= 0 {
+		return nil, errors.New("invalid_limit")
+	}
+	b := bitboard{stop: deadline{ctx: ctx}}
+	if !b.init(g) {
+		return nil, nil
+	}
+	var solutions []Grid
+	b.limit = limit
+	b.visit = func(solution Grid) {
+		solutions = append(solutions, solution)
+	}
+	b.search()
+	return solutions, b.stop.err
+}
+
+// trailEntry records a cell's candidates before a change, for undo.
+type trailEntry struct {
+	idx  uint8
+	mask uint16
+}
+
+// bitboard is the search state: candidates per cell (bit d for digit d) and whether the
+// cell's single candidate has been placed and removed from its peers.
+type bitboard struct {
+	cand     [81]uint16
+	solved   [81]bool
+	unsolved int
+
+	trail []trailEntry
+	queue []int
+
+	count, limit int
+	visit        func(Grid)
+	stop         deadline
+}
+
+func (b *bitboard) init(g Grid) bool {
+	if ValidateNoConflicts(g) != nil {
+		return false
+	}
+	for i := range b.cand {
+		b.cand[i] = allDigits
+	}
+	b.unsolved = 81
+	b.trail = make([]trailEntry, 0, 1024)
+	b.queue = make([]int, 0, 81)
+	for i, v := range g {
+		if v != 0 && !b.assign(i, uint16(1)<<v) {
+			return false
+		}
+	}
+	return true
+}
+
+// set narrows a cell's candidates, recording the old mask.
+func (b *bitboard) set(idx int, mask uint16) {
+	b.trail = append(b.trail, trailEntry{idx: uint8(idx), mask: b.cand[idx]})
+	b.cand[idx] = mask
+}
+
+// undo restores the state to trail length mark. A solved cell is unsolved when the
+// entry that narrowed it to one candidate is undone; assign always places a cell in the
+// same step that narrows it.
+func (b *bitboard) undo(mark int) {
+	for len(b.trail) > mark {
+		e := b.trail[len(b.trail)-1]
+		b.trail = b.trail[:len(b.trail)-1]
+		if b.solved[e.idx] && e.mask&(e.mask-1) != 0 {
+			b.solved[e.idx] = false
+			b.unsolved++
+		}
+		b.cand[e.idx] = e.mask
+	}
+}
+
+// assign places the digit bit in idx and propagates naked singles. It reports false on
+// a contradiction.
+func (b *bitboard) assign(idx int, bit uint16) bool {
+	b.queue = append(b.queue[:0], idx)
+	if b.cand[idx]&bit == 0 {
+		return false
+	}
+	if b.cand[idx] != bit {
+		b.set(idx, bit)
+	}
+	for len(b.queue) > 0 {
+		i := b.queue[len(b.queue)-1]
+		b.queue = b.queue[:len(b.queue)-1]
+		if b.solved[i] {
+			continue
+		}
+		d := b.cand[i]
+		b.solved[i] = true
+		b.unsolved--
+		for _, p := range peers[i] {
+			m := b.cand[p]
+			if m&d == 0 {
+				continue
+			}
+			m &^= d
+			if m == 0 {
+				return false
+			}
+			b.set(p, m)
+			if m&(m-1) == 0 {
+				b.queue = append(b.queue, p)
+			}
+		}
+	}
+	return true
+}
+
+// hiddenSingles places digits that have one spot left in a unit until none remain.
+// It reports false on a contradiction.
+func (b *bitboard) hiddenSingles() bool {
+	for changed := true; changed && b.unsolved > 0; {
+		changed = false
+		for u := 0; u < 27; u++ {
+			var once, twice, placed uint16
+			for _, i := range units[u] {
+				m := b.cand[i]
+				if b.solved[i] {
+					placed |= m
+					continue
+				}
+				twice |= once & m
+				once |= m
+			}
+			if once|placed != allDigits {
+				return false
+			}
+			hidden := once &^ twice &^ placed
+			for hidden != 0 {
+				bit := hidden & -hidden
+				hidden &^= bit
+				for _, i := range units[u] {
+					if !b.solved[i] && b.cand[i]&bit != 0 {
+						if !b.assign(i, bit) {
+							return false
+						}
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// pointing removes a digit from a row or column outside a box when, inside the box,
+// the digit is confined to that line. changed reports eliminations; ok is false on a
+// contradiction.
+func (b *bitboard) pointing() (changed, ok bool) {
+	for box := 0; box < 9; box++ {
+		var rows, cols [3]uint16
+		for k, i := range units[18+box] {
+			if b.solved[i] {
+				continue
+			}
+			rows[k/3] |= b.cand[i]
+			cols[k%3] |= b.cand[i]
+		}
+		for k := 0; k < 3; k++ {
+			for _, line := range [2]struct {
+				only uint16
+				unit int
+			}{
+				{rows[k] &^ (rows[(k+1)%3] | rows[(k+2)%3]), (box/3)*3 + k},
+				{cols[k] &^ (cols[(k+1)%3] | cols[(k+2)%3]), 9 + (box%3)*3 + k},
+			} {
+				if line.only == 0 {
+					continue
+				}
+				for _, i := range units[line.unit] {
+					m := b.cand[i]
+					if b.solved[i] || boxOf(i) == box || m&line.only == 0 {
+						continue
+					}
+					m &^= line.only
+					if m == 0 {
+						return false, false
+					}
+					changed = true
+					if m&(m-1) == 0 {
+						if !b.assign(i, m) {
+							return false, false
+						}
+						continue
+					}
+					b.set(i, m)
+				}
+			}
+		}
+	}
+	return changed, true
+}
+
+// propagate applies hidden singles and pointing until neither makes progress. It
+// reports false on a contradiction.
+func (b *bitboard) propagate() bool {
+	for {
+		if !b.hiddenSingles() {
+			return false
+		}
+		if b.unsolved == 0 {
+			return true
+		}
+		changed, ok := b.pointing()
+		if !ok {
+			return false
+		}
+		if !changed {
+			return true
+		}
+	}
+}
+
+// grid returns the placed digits, with unsolved cells empty.
+func (b *bitboard) grid() Grid {
+	var g Grid
+	for i, m := range b.cand {
+		if b.solved[i] {
+			g[i] = uint8(bits.TrailingZeros16(m))
+		}
+	}
+	return g
+}
+
+// branchCell returns the unsolved cell with the fewest candidates.
+func (b *bitboard) branchCell() int {
+	best, bestCount := -1, 10
+	for i, m := range b.cand {
+		if b.solved[i] {
+			continue
+		}
+		if n := bits.OnesCount16(m); n < bestCount {
+			best, bestCount = i, n
+			if n == 2 {
+				break
+			}
+		}
+	}
+	return best
+}
+
+func (b *bitboard) search() {
+	if b.stop.expired() || !b.propagate() {
+		return
+	}
+	if b.unsolved == 0 {
+		b.count++
+		if b.visit != nil {
+			b.visit(b.grid())
+		}
+		return
+	}
+
+	best := b.branchCell()
+	mask := b.cand[best]
+	for mask != 0 {
+		bit := mask & -mask
+		mask &^= bit
+
+		mark := len(b.trail)
+		if b.assign(best, bit) {
+			b.search()
+		}
+		b.undo(mark)
+		if b.count >= b.limit || b.stop.err != nil {
+			return
+		}
+	}
+}
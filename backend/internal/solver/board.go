@@ -0,0 +1,299 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// boxShapes gives the box height and width for each supported grid size.
+var boxShapes = map[int][2]int{
+	4:  {2, 2},
+	6:  {2, 3},
+	9:  {3, 3},
+	12: {3, 4},
+	16: {4, 4},
+}
+
+// ValidSize reports whether n is a supported grid size (4, 6, 9, 12 or 16).
+func ValidSize(n int) bool {
+	_, ok := boxShapes[n]
+	return ok
+}
+
+// BoxShape returns the box height and width of a supported grid size, e.g. 2 and 3 for
+// 6x6.
+func BoxShape(size int) (rows, cols int) {
+	shape := boxShapes[size]
+	return shape[0], shape[1]
+}
+
+// Board is a size-generic grid with classic rules. Cells hold 0 for empty or 1..Size.
+// Grid remains the 9x9 type used by the technique engine and variants.
+type Board struct {
+	Size  int
+	Cells []uint8
+}
+
+// EmptyChar is the character for an empty cell. 16x16 grids use hex digits 0-F for the
+// values 1-16, so their empty cells are '.'.
+func EmptyChar(size int) byte {
+	if size == 16 {
+		return '.'
+	}
+	return '0'
+}
+
+// DigitChar returns the character for value v (1..size): 1-9 then A, B, ... on 12x12,
+// and hex 0-F on 16x16.
+func DigitChar(size int, v uint8) byte {
+	if v == 0 {
+		return EmptyChar(size)
+	}
+	if size == 16 {
+		return "0123456789ABCDEF"[v-1]
+	}
+	if v <= 9 {
+		return '0' + v
+	}
+	return 'A' + v - 10
+}
+
+// ParseDigit parses a cell character for the given size. '.' is always empty.
+func ParseDigit(size int, ch byte) (v uint8, ok bool) {
+	if ch == '.' || ch == EmptyChar(size) {
+		return 0, true
+	}
+	if ch >= 'a' && ch <= 'z' {
+		ch -= 'a' - 'A'
+	}
+	var n int
+	switch {
+	case size == 16 && ch >= '0' && ch <= '9':
+		n = int(ch-'0') + 1
+	case size == 16 && ch >= 'A' && ch <= 'F':
+		n = int(ch-'A') + 11
+	case size != 16 && ch >= '1' && ch <= '9':
+		n = int(ch - '0')
+	case size != 16 && ch >= 'A' && ch <= 'Z':
+		n = int(ch-'A') + 10
+	default:
+		return 0, false
+	}
+	if n > size {
+		return 0, false
+	}
+	return uint8(n), true
+}
+
+// ParseBoard parses a size*size string of cells, ignoring whitespace, and returns the
+// normalized string and board.
+func ParseBoard(input string, size int) (string, Board, error) {
+	if !ValidSize(size) {
+		return "", Board{}, errors.New("invalid_size")
+	}
+	s := strings.Join(strings.Fields(input), "")
+	n := size * size
+	if len(s) != n {
+		return "", Board{}, fmt.Errorf("givens_must_be_%d_chars", n)
+	}
+
+	b := Board{Size: size, Cells: make([]uint8, n)}
+	for i := 0; i < n; i++ {
+		v, ok := ParseDigit(size, s[i])
+		if !ok {
+			return "", Board{}, fmt.Errorf("invalid_char_at_%d", i)
+		}
+		b.Cells[i] = v
+	}
+	return b.String(), b, nil
+}
+
+// String returns the board in its normalized form.
+func (b Board) String() string {
+	out := make([]byte, len(b.Cells))
+	for i, v := range b.Cells {
+		out[i] = DigitChar(b.Size, v)
+	}
+	return string(out)
+}
+
+// Validate checks that no row, column or box repeats a digit.
+func (b Board) Validate() error {
+	_, _, _, err := b.usedMasks()
+	return err
+}
+
+// CountBoardSolutions counts the solutions of a board up to limit. Like CountSolutions, a
+// board with conflicting givens has no solutions.
+func CountBoardSolutions(b Board, limit int) (int, error) {
+	return CountBoardSolutionsContext(context.Background(), b, limit)
+}
+
+// FindBoardSolutions returns up to limit solutions of a board.
+func FindBoardSolutions(b Board, limit int) ([]Board, error) {
+	return FindBoardSolutionsContext(context.Background(), b, limit)
+}
+
+// CountBoardSolutionsContext is CountBoardSolutions, stopping with ErrTimeout once ctx
+// is done.
+func CountBoardSolutionsContext(ctx context.Context, b Board, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, errors.New("invalid_limit")
+	}
+	s, err := newBoardSearch(b, limit, nil)
+	if err != nil {
+		return 0, nil
+	}
+	s.stop.ctx = ctx
+	s.dfs()
+	return s.count, s.stop.err
+}
+
+// FindBoardSolutionsContext is FindBoardSolutions, stopping with ErrTimeout once ctx is
+// done.
+func FindBoardSolutionsContext(ctx context.Context, b Board, limit int) ([]Board, error) {
+	if limit <= 0 {
+		return nil, errors.New("invalid_limit")
+	}
+	var solutions []Board
+	s, err := newBoardSearch(b, limit, func(cells []uint8) {
+		solutions = append(solutions, Board{Size: b.Size, Cells: append([]uint8(nil), cells...)})
+	})
+	if err != nil {
+		return nil, nil
+	}
+	s.stop.ctx = ctx
+	s.dfs()
+	return solutions, s.stop.err
+}
+
+func (b Board) boxOf(idx int) int {
+	shape := boxShapes[b.Size]
+	r, c := idx/b.Size, idx%b.Size
+	return (r/shape[0])*(b.Size/shape[1]) + c/shape[1]
+}
+
+// usedMasks returns the digits used per row, column and box (bit d for digit d).
+func (b Board) usedMasks() (rows, cols, boxes []uint32, err error) {
+	if !ValidSize(b.Size) || len(b.Cells) != b.Size*b.Size {
+		return nil, nil, nil, errors.New("invalid_size")
+	}
+	rows = make([]uint32, b.Size)
+	cols = make([]uint32, b.Size)
+	boxes = make([]uint32, b.Size)
+	for i, v := range b.Cells {
+		if v == 0 {
+			continue
+		}
+		if int(v) > b.Size {
+			return nil, nil, nil, errors.New("invalid_digit")
+		}
+		bit := uint32(1) << v
+		r, c, bx := i/b.Size, i%b.Size, b.boxOf(i)
+		switch {
+		case rows[r]&bit != 0:
+			return nil, nil, nil, errors.New("row_conflict")
+		case cols[c]&bit != 0:
+			return nil, nil, nil, errors.New("col_conflict")
+		case boxes[bx]&bit != 0:
+			return nil, nil, nil, errors.New("box_conflict")
+		}
+		rows[r] |= bit
+		cols[c] |= bit
+		boxes[bx] |= bit
+	}
+	return rows, cols, boxes, nil
+}
+
+// boardSearch is the backtracking solver for boards: it places digits in place and
+// undoes them, picking the cell with the fewest candidates each time.
+type boardSearch struct {
+	b                 Board
+	box               []int
+	rows, cols, boxes []uint32
+	all               uint32
+	count, limit      int
+	visit             func([]uint8)
+	stop              deadline
+}
+
+func newBoardSearch(b Board, limit int, visit func([]uint8)) (*boardSearch, error) {
+	rows, cols, boxes, err := b.usedMasks()
+	if err != nil {
+		return nil, err
+	}
+	s := &boardSearch{
+		b:     Board{Size: b.Size, Cells: append([]uint8(nil), b.Cells...)},
+		box:   make([]int, len(b.Cells)),
+		rows:  rows,
+		cols:  cols,
+		boxes: boxes,
+		all:   (uint32(1)<<(b.Size+1) - 1) &^ 1,
+		limit: limit,
+		visit: visit,
+	}
+	for i := range s.box {
+		s.box[i] = b.boxOf(i)
+	}
+	return s, nil
+}
+
+func (s *boardSearch) dfs() {
+	if s.stop.expired() {
+		return
+	}
+	n := s.b.Size
+	cells := s.b.Cells
+
+	best := -1
+	bestCount := n + 1
+	var bestCands uint32
+	for i, v := range cells {
+		if v != 0 {
+			continue
+		}
+		cands := s.all &^ (s.rows[i/n] | s.cols[i%n] | s.boxes[s.box[i]])
+		k := bits.OnesCount32(cands)
+		if k == 0 {
+			return
+		}
+		if k < bestCount {
+			best, bestCount, bestCands = i, k, cands
+			if k == 1 {
+				break
+			}
+		}
+	}
+	if best == -1 {
+		s.count++
+		if s.visit != nil {
+			s.visit(cells)
+		}
+		return
+	}
+
+	r, c, bx := best/n, best%n, s.box[best]
+	for d := 1; d <= n; d++ {
+		bit := uint32(1) << d
+		if bestCands&bit == 0 {
+			continue
+		}
+		cells[best] = uint8(d)
+		s.rows[r] |= bit
+		s.cols[c] |= bit
+		s.boxes[bx] |= bit
+
+		s.dfs()
+
+		cells[best] = 0
+		s.rows[r] &^= bit
+		s.cols[c] &^= bit
+		s.boxes[bx] &^= bit
+		if s.count >= s.limit || s.stop.err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBoardDigits(t *testing.T) {
+	t.Parallel()
+
+	// 16x16 givens use hex digits 0-F for 1-16, so only '.' is empty.
+	row := "0123456789abcdef"
+	normalized, b, err := ParseBoard(row+strings.Repeat(".", 240), 16)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if b.Cells[0] != 1 || b.Cells[15] != 16 || b.Cells[16] != 0 {
+		t.Fatalf("unexpected cells %v", b.Cells[:17])
+	}
+	if normalized[:17] != "0123456789ABCDEF." {
+		t.Fatalf("unexpected normalized form %s", normalized[:17])
+	}
+
+	tests := []struct {
+		input string
+		size  int
+		want  string
+	}{
+		{strings.Repeat("0", 16), 5, "invalid_size"},
+		{strings.Repeat("0", 15), 4, "givens_must_be_16_chars"},
+		{"5" + strings.Repeat("0", 15), 4, "invalid_char_at_0"},
+		{"D" + strings.Repeat("0", 143), 12, "invalid_char_at_0"},
+		{"G" + strings.Repeat(".", 255), 16, "invalid_char_at_0"},
+	}
+	for _, tt := range tests {
+		if _, _, err := ParseBoard(tt.input, tt.size); err == nil || err.Error() != tt.want {
+			t.Fatalf("size %d: expected %s, got %v", tt.size, tt.want, err)
+		}
+	}
+
+	// The two 2x3 boxes in the top band of a 6x6 grid split at column 3.
+	cells := "100000" + "001000" + strings.Repeat("0", 24)
+	_, six, _ := ParseBoard(cells, 6)
+	if err := six.Validate(); err == nil || err.Error() != "box_conflict" {
+		t.Fatalf("expected box_conflict, got %v", err)
+	}
+	_, six, _ = ParseBoard("100000"+"000100"+strings.Repeat("0", 24), 6)
+	if err := six.Validate(); err != nil {
+		t.Fatalf("unexpected conflict %v", err)
+	}
+}
+
+func TestBoardSolutionsForEachSize(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{4, 6, 9, 12, 16} {
+		empty := Board{Size: size, Cells: make([]uint8, size*size)}
+		solutions, err := FindBoardSolutions(empty, 1)
+		if err != nil || len(solutions) != 1 {
+			t.Fatalf("size %d: expected a solution, got %v", size, err)
+		}
+		solution := solutions[0]
+		if err := solution.Validate(); err != nil {
+			t.Fatalf("size %d: invalid solution: %v", size, err)
+		}
+		if _, round, err := ParseBoard(solution.String(), size); err != nil || round.String() != solution.String() {
+			t.Fatalf("size %d: solution does not round-trip: %v", size, err)
+		}
+		if size > 9 {
+			continue
+		}
+
+		// Carve givens while the board stays unique and check the solution is recovered.
+		b := Board{Size: size, Cells: append([]uint8(nil), solution.Cells...)}
+		for i := range b.Cells {
+			v := b.Cells[i]
+			b.Cells[i] = 0
+			if n, _ := CountBoardSolutions(b, 2); n != 1 {
+				b.Cells[i] = v
+			}
+		}
+		found, err := FindBoardSolutions(b, 2)
+		if err != nil || len(found) != 1 || found[0].String() != solution.String() {
+			t.Fatalf("size %d: expected the carved board to keep its unique solution", size)
+		}
+	}
+}
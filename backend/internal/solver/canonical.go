@@ -0,0 +1,159 @@
+package solver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Canonical returns the minlex form of a classic grid: the lexicographically smallest
+// grid, reading empty cells as 0, over every relabeling of the digits, permutation of
+// rows within a band, columns within a stack, bands, stacks, and transposition. Rotations
+// and reflections are products of these. Two grids are the same puzzle exactly when
+// their canonical forms are equal.
+func Canonical(g Grid) Grid {
+	c := canonicalizer{}
+	var arranged Grid
+	for _, transpose := range [2]bool{false, true} {
+		for _, stacks := range permutations3 {
+			for _, c0 := range permutations3 {
+				for _, c1 := range permutations3 {
+					for _, c2 := range permutations3 {
+						within := [3][3]int{c0, c1, c2}
+						for col := 0; col < 9; col++ {
+							stack := stacks[col/3]
+							src := stack*3 + within[stack][col%3]
+							for row := 0; row < 9; row++ {
+								if transpose {
+									arranged[row*9+col] = g[src*9+row]
+								} else {
+									arranged[row*9+col] = g[row*9+src]
+								}
+							}
+						}
+						c.rows(&arranged, 0, 0, [10]uint8{}, 1)
+					}
+				}
+			}
+		}
+	}
+	return c.best
+}
+
+// Fingerprint is a stable hex digest of a grid's canonical form, equal for two grids
+// exactly when they are the same puzzle.
+func Fingerprint(g Grid) string {
+	canonical := Canonical(g)
+	sum := sha256.Sum256([]byte(canonical.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+var permutations3 = [6][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+
+// canonicalizer keeps the smallest grid seen so far and the one being built.
+type canonicalizer struct {
+	best, current Grid
+	found         bool
+	// bands holds the source band of each band placed in current.
+	bands [3]int
+}
+
+// rows picks the rows of a column-arranged grid for positions pos onward. At each
+// position only the rows that relabel smallest can lead to the minimum, so it branches
+// on ties alone. labels maps original digits to relabeled ones, next being the next
+// label to hand out.
+func (c *canonicalizer) rows(g *Grid, pos int, used uint16, labels [10]uint8, next uint8) {
+	if pos == 9 {
+		if !c.found || lessPrefix(&c.current, &c.best, 81) {
+			c.best = c.current
+			c.found = true
+		}
+		return
+	}
+
+	var candidates [9]struct {
+		row    [9]uint8
+		labels [10]uint8
+		next   uint8
+		source int
+	}
+	n := 0
+	for r := 0; r < 9; r++ {
+		if used&(1<<r) != 0 {
+			continue
+		}
+		// A band is taken whole: its first row opens it and the next two follow from it.
+		if pos%3 == 0 {
+			if used&(7<<(r/3*3)) != 0 {
+				continue
+			}
+		} else if r/3 != c.bands[pos/3] {
+			continue
+		}
+
+		cand := &candidates[n]
+		cand.labels, cand.next, cand.source = labels, next, r
+		for col := 0; col < 9; col++ {
+			v := g[r*9+col]
+			if v != 0 {
+				if cand.labels[v] == 0 {
+					cand.labels[v] = cand.next
+					cand.next++
+				}
+				v = cand.labels[v]
+			}
+			cand.row[col] = v
+		}
+		n++
+	}
+
+	minRow := candidates[0].row
+	for i := 1; i < n; i++ {
+		if compareRows(candidates[i].row, minRow) < 0 {
+			minRow = candidates[i].row
+		}
+	}
+	// Give up once current cannot stay at or below best.
+	if c.found && !lessPrefix(&c.current, &c.best, pos*9) {
+		if lessPrefix(&c.best, &c.current, pos*9) {
+			return
+		}
+		var bestRow [9]uint8
+		copy(bestRow[:], c.best[pos*9:pos*9+9])
+		if compareRows(minRow, bestRow) > 0 {
+			return
+		}
+	}
+
+	copy(c.current[pos*9:pos*9+9], minRow[:])
+	for i := 0; i < n; i++ {
+		if candidates[i].row != minRow {
+			continue
+		}
+		if pos%3 == 0 {
+			c.bands[pos/3] = candidates[i].source / 3
+		}
+		c.rows(g, pos+1, used|1<<candidates[i].source, candidates[i].labels, candidates[i].next)
+	}
+}
+
+// lessPrefix reports whether the first n cells of a sort before those of b.
+func lessPrefix(a, b *Grid, n int) bool {
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func compareRows(a, b [9]uint8) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// transform applies a random symmetry of the grid: digit relabeling, row and column
+// permutations within bands and stacks, band and stack swaps, and transposition.
+func transform(g Grid, rng *rand.Rand) Grid {
+	perm := func() [9]int {
+		var p [9]int
+		bands := rng.Perm(3)
+		for b := 0; b < 3; b++ {
+			for k, r := range rng.Perm(3) {
+				p[b*3+k] = bands[b]*3 + r
+			}
+		}
+		return p
+	}
+	rows, cols := perm(), perm()
+	digits := rng.Perm(9)
+	transpose := rng.Intn(2) == 1
+
+	var out Grid
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			v := g[rows[r]*9+cols[c]]
+			if v != 0 {
+				v = uint8(digits[v-1]) + 1
+			}
+			if transpose {
+				out[c*9+r] = v
+			} else {
+				out[r*9+c] = v
+			}
+		}
+	}
+	return out
+}
+
+func TestCanonicalIsInvariantUnderSymmetries(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+	for _, p := range hardPuzzles {
+		_, g, err := ParseAndNormalize(p)
+		if err != nil {
+			t.Fatalf("parse %s: %v", p, err)
+		}
+		want := Canonical(g)
+		if Canonical(want) != want {
+			t.Fatalf("canonical form is not a fixed point: %s", want)
+		}
+		for i := 0; i < 5; i++ {
+			if got := Canonical(transform(g, rng)); got != want {
+				t.Fatalf("%s: transformed grid canonicalizes to\n%s, want\n%s", p, got, want)
+			}
+		}
+		if Fingerprint(transform(g, rng)) != Fingerprint(g) {
+			t.Fatalf("%s: fingerprints differ", p)
+		}
+	}
+}
+
+func TestCanonicalSeparatesDifferentPuzzles(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]string)
+	for _, p := range hardPuzzles {
+		_, g, _ := ParseAndNormalize(p)
+		fp := Fingerprint(g)
+		if other, ok := seen[fp]; ok {
+			t.Fatalf("%s and %s share a fingerprint", p, other)
+		}
+		seen[fp] = p
+	}
+
+	// Rotating by 90 degrees is a transposition followed by a column reversal.
+	_, g, _ := ParseAndNormalize(hardPuzzles[0])
+	var rotated Grid
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			rotated[c*9+8-r] = g[r*9+c]
+		}
+	}
+	if Canonical(rotated) != Canonical(g) {
+		t.Fatalf("rotation changed the canonical form")
+	}
+}
+
+func BenchmarkCanonical(b *testing.B) {
+	grids := make([]Grid, len(hardPuzzles))
+	for i, p := range hardPuzzles {
+		_, grids[i], _ = ParseAndNormalize(p)
+	}
+	for i := 0; i < b.N; i++ {
+		for _, g := range grids {
+			Canonical(g)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package solver
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Constraint is a rule the backtracking search enforces on top of the layout's units
+// (rows, columns, boxes), so every rule set runs on the same search core.
+type Constraint interface {
+	// Name identifies the rule in error codes, e.g. "anti_knight" gives "anti_knight_conflict".
+	Name() string
+	// Allowed returns the digits the empty cell idx may take given the digits placed in g.
+	Allowed(g *Grid, idx int) uint16
+}
+
+// Rule names stored with a puzzle.
+const (
+	RuleDiagonal       = "diagonal"
+	RuleAntiKnight     = "anti_knight"
+	RuleAntiKing       = "anti_king"
+	RuleNonConsecutive = "non_consecutive"
+)
+
+// rules maps rule names to their constraints. RegisterRule adds more.
+var rules = map[string]Constraint{}
+
+func init() {
+	RegisterRule(newPeerConstraint(RuleDiagonal, func(a, b int) bool {
+		ra, ca, rb, cb := a/9, a%9, b/9, b%9
+		return (ra == ca && rb == cb) || (ra+ca == 8 && rb+cb == 8)
+	}))
+	RegisterRule(newPeerConstraint(RuleAntiKnight, func(a, b int) bool {
+		dr, dc := abs(a/9-b/9), abs(a%9-b%9)
+		return (dr == 1 && dc == 2) || (dr == 2 && dc == 1)
+	}))
+	RegisterRule(newPeerConstraint(RuleAntiKing, func(a, b int) bool {
+		return abs(a/9-b/9) <= 1 && abs(a%9-b%9) <= 1
+	}))
+	RegisterRule(nonConsecutive{})
+}
+
+// RegisterRule makes a constraint available to Variant.Rules under its name.
+func RegisterRule(c Constraint) {
+	rules[c.Name()] = c
+}
+
+// NormalizeRules sorts and deduplicates rule names and rejects unknown ones.
+func NormalizeRules(names []string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := rules[name]; !ok {
+			return nil, errors.New("unknown_rule")
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// checkConstraint reports the first placed digit that c does not allow.
+func checkConstraint(g Grid, c Constraint) error {
+	for i := 0; i < 81; i++ {
+		v := g[i]
+		if v == 0 {
+			continue
+		}
+		g[i] = 0
+		allowed := c.Allowed(&g, i)
+		g[i] = v
+		if allowed&(uint16(1)<<v) == 0 {
+			return errors.New(c.Name() + "_conflict")
+		}
+	}
+	return nil
+}
+
+// peerConstraint forbids repeating a digit between cells related by a fixed pattern,
+// e.g. a knight's move apart.
+type peerConstraint struct {
+	name  string
+	peers [81][]int
+}
+
+func newPeerConstraint(name string, related func(a, b int) bool) *peerConstraint {
+	c := &peerConstraint{name: name}
+	for a := 0; a < 81; a++ {
+		for b := 0; b < 81; b++ {
+			if a != b && related(a, b) {
+				c.peers[a] = append(c.peers[a], b)
+			}
+		}
+	}
+	return c
+}
+
+func (c *peerConstraint) Name() string { return c.name }
+
+func (c *peerConstraint) Allowed(g *Grid, idx int) uint16 {
+	var used uint16
+	for _, p := range c.peers[idx] {
+		used |= uint16(1) << g[p]
+	}
+	return allDigits &^ used
+}
+
+// nonConsecutive forbids orthogonally adjacent cells from holding consecutive digits.
+type nonConsecutive struct{}
+
+func (nonConsecutive) Name() string { return RuleNonConsecutive }
+
+func (nonConsecutive) Allowed(g *Grid, idx int) uint16 {
+	r, c := idx/9, idx%9
+	var banned uint16
+	for _, n := range [4][2]int{{r - 1, c}, {r + 1, c}, {r, c - 1}, {r, c + 1}} {
+		if n[0] < 0 || n[0] > 8 || n[1] < 0 || n[1] > 8 {
+			continue
+		}
+		if v := g[n[0]*9+n[1]]; v != 0 {
+			banned |= uint16(1)<<(v-1) | uint16(1)<<(v+1)
+		}
+	}
+	return allDigits &^ banned
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,72 @@
+package solver
+
+import "testing"
+
+func TestVariantRulesAreEnforced(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{RuleDiagonal, RuleAntiKnight, RuleAntiKing, RuleNonConsecutive} {
+		v := Variant{Rules: []string{name}}
+		var empty Grid
+		solutions, err := v.FindSolutions(empty, 2)
+		if err != nil {
+			t.Fatalf("%s: find: %v", name, err)
+		}
+		if len(solutions) == 0 {
+			t.Fatalf("%s: expected a solution", name)
+		}
+		for _, s := range solutions {
+			if err := v.Validate(s); err != nil {
+				t.Fatalf("%s: solution breaks the rules: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestVariantValidateReportsRuleConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule string
+		a, b int
+		da   uint8
+		db   uint8
+	}{
+		{RuleDiagonal, 0, 80, 1, 1},
+		{RuleAntiKnight, 2, 13, 1, 1},
+		{RuleAntiKing, 2, 12, 1, 1},
+		{RuleNonConsecutive, 0, 1, 1, 2},
+	}
+	for _, tt := range tests {
+		var g Grid
+		g[tt.a], g[tt.b] = tt.da, tt.db
+		if err := ValidateNoConflicts(g); err != nil {
+			t.Fatalf("%s: test grid breaks classic rules: %v", tt.rule, err)
+		}
+		err := Variant{Rules: []string{tt.rule}}.Validate(g)
+		if err == nil || err.Error() != tt.rule+"_conflict" {
+			t.Fatalf("%s: expected %s_conflict, got %v", tt.rule, tt.rule, err)
+		}
+		if n, _ := (Variant{Rules: []string{tt.rule}}).CountSolutions(g, 1); n != 0 {
+			t.Fatalf("%s: expected no solutions, got %d", tt.rule, n)
+		}
+	}
+}
+
+func TestNormalizeRules(t *testing.T) {
+	t.Parallel()
+
+	got, err := NormalizeRules([]string{"anti_knight", " diagonal", "anti_knight", ""})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if len(got) != 2 || got[0] != RuleAntiKnight || got[1] != RuleDiagonal {
+		t.Fatalf("unexpected rules %v", got)
+	}
+	if _, err := NormalizeRules([]string{"sandwich"}); err == nil {
+		t.Fatalf("expected an error for an unknown rule")
+	}
+	if _, err := (Variant{Rules: []string{"sandwich"}}).CountSolutions(Grid{}, 1); err == nil {
+		t.Fatalf("expected an error for an unknown rule")
+	}
+}
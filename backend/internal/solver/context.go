@@ -0,0 +1,39 @@
+package solver
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTimeout is returned by the Context solving functions when the context is cancelled
+// or its deadline passes before the search finishes.
+var ErrTimeout = errors.New("solve_timeout")
+
+// CountSolutionsContext is CountSolutions, stopping with ErrTimeout once ctx is done.
+func CountSolutionsContext(ctx context.Context, g Grid, limit int) (int, error) {
+	return Variant{}.CountSolutionsContext(ctx, g, limit)
+}
+
+// FindSolutionsContext is FindSolutions, stopping with ErrTimeout once ctx is done.
+func FindSolutionsContext(ctx context.Context, g Grid, limit int) ([]Grid, error) {
+	return Variant{}.FindSolutionsContext(ctx, g, limit)
+}
+
+// deadline lets a search poll its context every few hundred nodes rather than on each.
+type deadline struct {
+	ctx   context.Context
+	nodes int
+	err   error
+}
+
+// expired reports whether the search should stop, recording ErrTimeout in err.
+func (d *deadline) expired() bool {
+	if d.err != nil {
+		return true
+	}
+	d.nodes++
+	if d.nodes&255 == 1 && d.ctx.Err() != nil {
+		d.err = ErrTimeout
+	}
+	return d.err != nil
+}
@@ -0,0 +1,56 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestContextSolvingStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := CountSolutionsContext(ctx, Grid{}, 1_000_000); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("classic: expected ErrTimeout, got %v", err)
+	}
+	diagonal := Variant{Rules: []string{RuleDiagonal}}
+	if _, err := diagonal.FindSolutionsContext(ctx, Grid{}, 1_000_000); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("variant: expected ErrTimeout, got %v", err)
+	}
+	if _, err := CountBoardSolutionsContext(ctx, Board{Size: 16, Cells: make([]uint8, 256)}, 1_000_000); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("board: expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestContextSolvingHonoursDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	count, err := CountSolutionsContext(ctx, Grid{}, 1<<30)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v after %d solutions", err, count)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("search ran %v past a 20ms deadline", elapsed)
+	}
+}
+
+func TestContextSolvingMatchesPlainSolving(t *testing.T) {
+	t.Parallel()
+
+	_, g, err := ParseGrid(ambiguousGivens)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want, _ := CountSolutions(g, 10)
+	got, err := CountSolutionsContext(context.Background(), g, 10)
+	if err != nil || got != want {
+		t.Fatalf("expected %d solutions, got %d (%v)", want, got, err)
+	}
+}
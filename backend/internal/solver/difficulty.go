@@ -0,0 +1,117 @@
+package solver
+
+import "math"
+
+// seRatings maps techniques to their Sudoku Explainer (SE) ratings. Techniques SE does not
+// know by name are rated as the chain or fish they are equivalent to.
+var seRatings = map[TechniqueID]float64{
+	TechniqueHiddenSingle:     1.5, // 1.2 inside a box, see stepSERating
+	TechniqueNakedSingle:      2.3,
+	TechniquePointingPair:     2.6,
+	TechniqueBoxLineReduction: 2.8,
+	TechniqueNakedPair:        3.0,
+	TechniqueXWing:            3.2,
+	TechniqueHiddenPair:       3.4,
+	TechniqueNakedTriple:      3.6,
+	TechniqueSwordfish:        3.8,
+	TechniqueHiddenTriple:     4.0,
+	TechniqueXYWing:           4.2,
+	TechniqueXYZWing:          4.4,
+	TechniqueNakedQuad:        5.0,
+	TechniqueJellyfish:        5.2,
+	TechniqueHiddenQuad:       5.4,
+	TechniqueSimpleColoring:   6.5,
+	TechniqueSkyscraper:       6.6,
+	TechniqueTwoStringKite:    6.6,
+	TechniqueTurbotFish:       6.6,
+	TechniqueWWing:            6.6,
+	TechniqueXYChain:          6.6,
+}
+
+// seRatingUnsolved is the floor for puzzles the engine cannot finish: SE's forcing chains start at 7.0.
+const seRatingUnsolved = 7.0
+
+// Rating is the technique engine's assessment of a puzzle.
+type Rating struct {
+	// Difficulty is on the same 1-10 scale as the frontend's calculateDifficulty.
+	Difficulty int
+	// SERating is a Sudoku Explainer style rating (e.g. 1.2, 2.3, 7.1) of the hardest step.
+	SERating float64
+	// Solved is false when the engine got stuck; such puzzles are rated 10 and at least 7.0 SE.
+	Solved bool
+	// Steps are the deductions the engine made, in order.
+	Steps []Step
+}
+
+// Rate runs the technique engine on a puzzle and grades it.
+func Rate(g Grid) Rating {
+	e := NewEngine(g)
+	steps := e.Solve()
+	solved := e.Solved()
+	return Rating{
+		Difficulty: DifficultyFromSteps(steps, solved),
+		SERating:   SERatingFromSteps(steps, solved),
+		Solved:     solved,
+		Steps:      steps,
+	}
+}
+
+// DifficultyFromSteps mirrors the frontend's calculateDifficulty: the base is the hardest
+// technique used, bumped one level when that technique was needed often enough.
+func DifficultyFromSteps(steps []Step, solved bool) int {
+	if !solved {
+		return 10
+	}
+	if len(steps) == 0 {
+		return 1
+	}
+
+	var counts [11]int
+	maxDifficulty := 1
+	for _, step := range steps {
+		counts[step.Difficulty]++
+		if step.Difficulty > maxDifficulty {
+			maxDifficulty = step.Difficulty
+		}
+	}
+
+	// Frequency needed at each level to bump to the next one. There is no bump
+	// from 9 to 10: Grandmaster requires actual level 10 techniques.
+	bumpAt := map[int]int{2: 8, 3: 6, 4: 5, 5: 4, 6: 3, 7: 3, 8: 3}
+	difficulty := maxDifficulty
+	if threshold, ok := bumpAt[maxDifficulty]; ok && counts[maxDifficulty] >= threshold {
+		difficulty++
+	}
+
+	if difficulty < 1 {
+		return 1
+	}
+	if difficulty > 10 {
+		return 10
+	}
+	return difficulty
+}
+
+// SERatingFromSteps returns the SE rating of the hardest step, rounded to one decimal.
+func SERatingFromSteps(steps []Step, solved bool) float64 {
+	hardest := 1.0
+	for _, step := range steps {
+		if r := stepSERating(step); r > hardest {
+			hardest = r
+		}
+	}
+	if !solved && hardest < seRatingUnsolved {
+		hardest = seRatingUnsolved
+	}
+	return math.Round(hardest*10) / 10
+}
+
+func stepSERating(step Step) float64 {
+	if step.Technique == TechniqueHiddenSingle && step.Difficulty == 1 {
+		return 1.2
+	}
+	if r, ok := seRatings[step.Technique]; ok {
+		return r
+	}
+	return seRatingUnsolved
+}
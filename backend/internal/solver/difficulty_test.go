@@ -0,0 +1,52 @@
+package solver
+
+import "testing"
+
+func TestRateMatchesTechniquesUsed(t *testing.T) {
+	t.Parallel()
+
+	_, easy, err := ParseAndNormalize(techniquePuzzles[0])
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if r := Rate(easy); !r.Solved || r.Difficulty > 3 || r.SERating > 2.3 {
+		t.Fatalf("expected an easy solved rating, got %d / SE %.1f (solved %v)", r.Difficulty, r.SERating, r.Solved)
+	}
+
+	_, xwing, err := ParseAndNormalize(techniquePuzzles[1])
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if r := Rate(xwing); !r.Solved || r.Difficulty < 6 || r.SERating < 3.2 {
+		t.Fatalf("expected X-Wing puzzle to rate at least 6 / SE 3.2, got %d / SE %.1f (solved %v)", r.Difficulty, r.SERating, r.Solved)
+	}
+
+	_, monster, err := ParseAndNormalize(techniquePuzzles[4])
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if r := Rate(monster); r.Solved || r.Difficulty != 10 || r.SERating < 7.0 {
+		t.Fatalf("expected unsolved puzzle to rate 10 / SE 7.0+, got %d / SE %.1f (solved %v)", r.Difficulty, r.SERating, r.Solved)
+	}
+}
+
+func TestDifficultyFromStepsFrequencyBump(t *testing.T) {
+	t.Parallel()
+
+	steps := make([]Step, 0, 5)
+	for i := 0; i < 4; i++ {
+		steps = append(steps, Step{Difficulty: 4})
+	}
+	if d := DifficultyFromSteps(steps, true); d != 4 {
+		t.Fatalf("expected 4, got %d", d)
+	}
+
+	steps = append(steps, Step{Difficulty: 4})
+	if d := DifficultyFromSteps(steps, true); d != 5 {
+		t.Fatalf("expected five level-4 steps to bump to 5, got %d", d)
+	}
+
+	if d := DifficultyFromSteps(nil, true); d != 1 {
+		t.Fatalf("expected trivial puzzle to rate 1, got %d", d)
+	}
+}
@@ -0,0 +1,204 @@
+package solver
+
+import (
+	"errors"
+	"sync"
+)
+
+// DLXSolver implements Solver with Knuth's Dancing Links over the exact-cover matrix of
+// classic Sudoku: 729 candidate rows (cell, digit) and 324 columns, one per cell, per
+// digit in each row, column and box.
+type DLXSolver struct{}
+
+// CountSolutions counts the solutions of a classic Sudoku up to limit.
+func (DLXSolver) CountSolutions(g Grid, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, errors.New("invalid_limit")
+	}
+	x, ok := newDLX(g)
+	if !ok {
+		return 0, nil
+	}
+	x.limit = limit
+	x.search()
+	return x.count, nil
+}
+
+// FindSolutions returns up to limit solutions of a classic Sudoku.
+func (DLXSolver) FindSolutions(g Grid, limit int) ([]Grid, error) {
+	if limit <= 0 {
+		return nil, errors.New("invalid_limit")
+	}
+	x, ok := newDLX(g)
+	if !ok {
+		return nil, nil
+	}
+	var solutions []Grid
+	x.limit = limit
+	x.visit = func(rows []int) {
+		solution := g
+		for _, r := range rows {
+			solution[r/9] = uint8(r%9) + 1
+		}
+		solutions = append(solutions, solution)
+	}
+	x.search()
+	return solutions, nil
+}
+
+const (
+	dlxColumns = 4 * 81
+	dlxRows    = 9 * 81
+	// Node 0 is the root, nodes 1-324 are the column headers, then four nodes per row.
+	dlxNodes = 1 + dlxColumns + 4*dlxRows
+)
+
+// dlx is the matrix as arrays of node links. size is indexed by column header node.
+type dlx struct {
+	left, right, up, down [dlxNodes]int32
+	col, row              [dlxNodes]int32
+	size                  [1 + dlxColumns]int32
+
+	solution     []int
+	count, limit int
+	visit        func(rows []int)
+}
+
+var (
+	dlxTemplate     dlx
+	dlxTemplateOnce sync.Once
+)
+
+// newDLX copies the full matrix and covers the givens' columns. It reports false when
+// the givens conflict.
+func newDLX(g Grid) (*dlx, bool) {
+	if ValidateNoConflicts(g) != nil {
+		return nil, false
+	}
+	dlxTemplateOnce.Do(buildDLXTemplate)
+	x := new(dlx)
+	*x = dlxTemplate
+
+	for idx, v := range g {
+		if v == 0 {
+			continue
+		}
+		first := dlxRowNode(idx*9 + int(v) - 1)
+		j := first
+		for {
+			x.cover(x.col[j])
+			j = x.right[j]
+			if j == first {
+				break
+			}
+		}
+	}
+	return x, true
+}
+
+// dlxRowNode is the first node of candidate row r (cell r/9, digit r%9+1).
+func dlxRowNode(r int) int32 {
+	return int32(1 + dlxColumns + 4*r)
+}
+
+func buildDLXTemplate() {
+	x := &dlxTemplate
+	for c := int32(0); c <= dlxColumns; c++ {
+		x.left[c] = c - 1
+		x.right[c] = c + 1
+		x.up[c] = c
+		x.down[c] = c
+		x.col[c] = c
+	}
+	x.left[0] = dlxColumns
+	x.right[dlxColumns] = 0
+
+	for r := 0; r < dlxRows; r++ {
+		idx, d := r/9, r%9
+		row, column := idx/9, idx%9
+		columns := [4]int32{
+			int32(1 + idx),
+			int32(1 + 81 + row*9 + d),
+			int32(1 + 162 + column*9 + d),
+			int32(1 + 243 + boxOf(idx)*9 + d),
+		}
+		first := dlxRowNode(r)
+		for k, c := range columns {
+			n := first + int32(k)
+			x.col[n] = c
+			x.row[n] = int32(r)
+			x.left[n] = first + int32((k+3)%4)
+			x.right[n] = first + int32((k+1)%4)
+			// Append to the bottom of the column.
+			x.up[n] = x.up[c]
+			x.down[n] = c
+			x.down[x.up[c]] = n
+			x.up[c] = n
+			x.size[c]++
+		}
+	}
+}
+
+func (x *dlx) cover(c int32) {
+	x.right[x.left[c]] = x.right[c]
+	x.left[x.right[c]] = x.left[c]
+	for i := x.down[c]; i != c; i = x.down[i] {
+		for j := x.right[i]; j != i; j = x.right[j] {
+			x.down[x.up[j]] = x.down[j]
+			x.up[x.down[j]] = x.up[j]
+			x.size[x.col[j]]--
+		}
+	}
+}
+
+func (x *dlx) uncover(c int32) {
+	for i := x.up[c]; i != c; i = x.up[i] {
+		for j := x.left[i]; j != i; j = x.left[j] {
+			x.size[x.col[j]]++
+			x.down[x.up[j]] = j
+			x.up[x.down[j]] = j
+		}
+	}
+	x.right[x.left[c]] = c
+	x.left[x.right[c]] = c
+}
+
+// search is Algorithm X, branching on the column with the fewest rows.
+func (x *dlx) search() {
+	if x.right[0] == 0 {
+		x.count++
+		if x.visit != nil {
+			x.visit(x.solution)
+		}
+		return
+	}
+
+	c := x.right[0]
+	for j := x.right[c]; j != 0; j = x.right[j] {
+		if x.size[j] < x.size[c] {
+			c = j
+		}
+	}
+	if x.size[c] == 0 {
+		return
+	}
+
+	x.cover(c)
+	for r := x.down[c]; r != c; r = x.down[r] {
+		x.solution = append(x.solution, int(x.row[r]))
+		for j := x.right[r]; j != r; j = x.right[j] {
+			x.cover(x.col[j])
+		}
+
+		x.search()
+
+		for j := x.left[r]; j != r; j = x.left[j] {
+			x.uncover(x.col[j])
+		}
+		x.solution = x.solution[:len(x.solution)-1]
+		if x.count >= x.limit {
+			break
+		}
+	}
+	x.uncover(c)
+}
@@ -0,0 +1,246 @@
+package solver
+
+import "fmt"
+
+const allDigits uint16 = 0x3FE
+
+// Unit indexes: 0-8 are rows, 9-17 are columns, 18-26 are boxes.
+var (
+	units     [27][9]int
+	cellUnits [81][3]int
+	peers     [81][20]int
+)
+
+func init() {
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			units[i][j] = i*9 + j
+			units[9+i][j] = j*9 + i
+			units[18+i][j] = ((i/3)*3+j/3)*9 + (i%3)*3 + j%3
+		}
+	}
+	for u := 0; u < 27; u++ {
+		for _, idx := range units[u] {
+			cellUnits[idx][u/9] = u
+		}
+	}
+	for idx := 0; idx < 81; idx++ {
+		n := 0
+		for other := 0; other < 81; other++ {
+			if sees(idx, other) {
+				peers[idx][n] = other
+				n++
+			}
+		}
+	}
+}
+
+// Engine is a human-style solver. It keeps a candidate grid and applies logical
+// techniques in order of difficulty instead of guessing.
+type Engine struct {
+	values     Grid
+	candidates [81]uint16
+	techniques []Technique
+}
+
+// NewEngine creates an engine whose candidates are derived from the placed values.
+func NewEngine(g Grid) *Engine {
+	e := &Engine{values: g, techniques: DefaultTechniques}
+	rows, cols, boxes := buildUsedMasks(g)
+	for i := 0; i < 81; i++ {
+		if g[i] != 0 {
+			continue
+		}
+		r := i / 9
+		c := i % 9
+		b := (r/3)*3 + (c / 3)
+		e.candidates[i] = (^(rows[r] | cols[c] | boxes[b])) & allDigits
+	}
+	return e
+}
+
+// Values returns the digits placed so far.
+func (e *Engine) Values() Grid {
+	return e.values
+}
+
+// Candidates returns the candidate bitmask of a cell (bit d set means digit d is possible).
+func (e *Engine) Candidates(idx int) uint16 {
+	return e.candidates[idx]
+}
+
+// RestrictCandidates keeps only the candidates of a cell that are also in mask,
+// e.g. to respect a player's pencil marks.
+func (e *Engine) RestrictCandidates(idx int, mask uint16) {
+	e.candidates[idx] &= mask
+}
+
+// Solved reports whether every cell has a digit.
+func (e *Engine) Solved() bool {
+	for _, v := range e.values {
+		if v == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// FindStep returns the easiest applicable step without applying it, or nil when stuck.
+func (e *Engine) FindStep() *Step {
+	if e.Solved() || e.broken() {
+		return nil
+	}
+	for _, t := range e.techniques {
+		if step := t.find(e); step != nil {
+			return step
+		}
+	}
+	return nil
+}
+
+// Apply places the step's digits and removes its eliminated candidates.
+func (e *Engine) Apply(step *Step) {
+	for _, p := range step.Placements {
+		e.place(p.Cell, p.Digit)
+	}
+	for _, el := range step.Eliminations {
+		e.candidates[el.Cell] &^= uint16(1) << el.Digit
+	}
+}
+
+// Solve applies steps until the grid is solved or no technique applies.
+func (e *Engine) Solve() []Step {
+	var steps []Step
+	for {
+		step := e.FindStep()
+		if step == nil {
+			return steps
+		}
+		e.Apply(step)
+		steps = append(steps, *step)
+	}
+}
+
+func (e *Engine) place(idx int, digit uint8) {
+	e.values[idx] = digit
+	e.candidates[idx] = 0
+	bit := uint16(1) << digit
+	for _, p := range peers[idx] {
+		e.candidates[p] &^= bit
+	}
+}
+
+// broken reports an empty cell without candidates, in which case no deduction is sound.
+func (e *Engine) broken() bool {
+	for i := 0; i < 81; i++ {
+		if e.values[i] == 0 && e.candidates[i] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// cellsWith returns the empty cells of a unit that still have digit as a candidate.
+// It returns nil when the digit is already placed in the unit.
+func (e *Engine) cellsWith(u int, digit uint8) []int {
+	bit := uint16(1) << digit
+	var out []int
+	for _, idx := range units[u] {
+		if e.values[idx] == digit {
+			return nil
+		}
+		if e.candidates[idx]&bit != 0 {
+			out = append(out, idx)
+		}
+	}
+	return out
+}
+
+// eliminationsFrom collects the cells in targets (minus skip) that still have digit as a candidate.
+func (e *Engine) eliminationsFrom(targets []int, digit uint8, skip func(int) bool) []Elimination {
+	bit := uint16(1) << digit
+	var out []Elimination
+	for _, idx := range targets {
+		if skip != nil && skip(idx) {
+			continue
+		}
+		if e.candidates[idx]&bit != 0 {
+			out = append(out, Elimination{Cell: idx, Digit: digit})
+		}
+	}
+	return out
+}
+
+// commonPeers returns the cells that see every cell in cells.
+func commonPeers(cells ...int) []int {
+	var out []int
+	for _, p := range peers[cells[0]] {
+		ok := true
+		for _, c := range cells[1:] {
+			if !sees(p, c) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func sees(a, b int) bool {
+	if a == b {
+		return false
+	}
+	return a/9 == b/9 || a%9 == b%9 || boxOf(a) == boxOf(b)
+}
+
+func boxOf(idx int) int {
+	return (idx/27)*3 + (idx%9)/3
+}
+
+func digitsOf(mask uint16) []uint8 {
+	out := make([]uint8, 0, 9)
+	for d := uint8(1); d <= 9; d++ {
+		if mask&(uint16(1)<<d) != 0 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func cellName(idx int) string {
+	return fmt.Sprintf("R%dC%d", idx/9+1, idx%9+1)
+}
+
+func unitName(u int) string {
+	switch u / 9 {
+	case 0:
+		return fmt.Sprintf("row %d", u+1)
+	case 1:
+		return fmt.Sprintf("column %d", u-8)
+	default:
+		return fmt.Sprintf("box %d", u-17)
+	}
+}
+
+// combinations calls fn with every k-sized subset of items until fn returns true.
+func combinations(items []int, k int, fn func([]int) bool) bool {
+	buf := make([]int, 0, k)
+	var rec func(start int) bool
+	rec = func(start int) bool {
+		if len(buf) == k {
+			return fn(buf)
+		}
+		for i := start; i <= len(items)-(k-len(buf)); i++ {
+			buf = append(buf, items[i])
+			if rec(i + 1) {
+				return true
+			}
+			buf = buf[:len(buf)-1]
+		}
+		return false
+	}
+	return rec(0)
+}
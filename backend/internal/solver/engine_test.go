@@ -0,0 +1,105 @@
+package solver
+
+import "testing"
+
+var techniquePuzzles = []string{
+	// Classic easy puzzle (singles only).
+	"530070000600195000098000060800060003400803001700020006060000280000419005000080079",
+	// X-Wing example.
+	"100000569492056108056109240009640801064010000218035604040500016905061402621000005",
+	// XY-Wing example.
+	"900040000000600031020000090000700020002935600070002000060000073510009000000080009",
+	// Arto Inkala's "world's hardest sudoku".
+	"800000000003600000070090200050007000000045700000100030001000068008500010090000400",
+	// Easter Monster.
+	"100000002090400050006000700050903000000070000000850040700000600030009080002000001",
+}
+
+// solveForTest finds the unique solution by fixing one digit at a time with CountSolutions.
+func solveForTest(t *testing.T, g Grid) Grid {
+	t.Helper()
+
+	if n, err := CountSolutions(g, 2); err != nil || n != 1 {
+		t.Fatalf("expected unique puzzle, got %d solutions (err %v)", n, err)
+	}
+	for i := 0; i < 81; i++ {
+		if g[i] != 0 {
+			continue
+		}
+		for d := uint8(1); d <= 9; d++ {
+			g[i] = d
+			if n, _ := CountSolutions(g, 1); n == 1 {
+				break
+			}
+		}
+	}
+	return g
+}
+
+func TestEngineStepsAgreeWithSolution(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range techniquePuzzles {
+		_, grid, err := ParseAndNormalize(in)
+		if err != nil {
+			t.Fatalf("parse %s: %v", in, err)
+		}
+		solution := solveForTest(t, grid)
+
+		e := NewEngine(grid)
+		for _, step := range e.Solve() {
+			for _, p := range step.Placements {
+				if solution[p.Cell] != p.Digit {
+					t.Fatalf("%s: %s placed %d at %s, solution has %d", in, step.Technique, p.Digit, cellName(p.Cell), solution[p.Cell])
+				}
+			}
+			for _, el := range step.Eliminations {
+				if solution[el.Cell] == el.Digit {
+					t.Fatalf("%s: %s eliminated the solution digit %d at %s", in, step.Technique, el.Digit, cellName(el.Cell))
+				}
+			}
+			if step.Difficulty < 1 || step.Difficulty > 10 {
+				t.Fatalf("%s: difficulty out of range: %d", in, step.Difficulty)
+			}
+		}
+	}
+}
+
+func TestEngineSolvesEasyPuzzleWithSingles(t *testing.T) {
+	t.Parallel()
+
+	_, grid, err := ParseAndNormalize(techniquePuzzles[0])
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	e := NewEngine(grid)
+	for _, step := range e.Solve() {
+		if step.Technique != TechniqueNakedSingle && step.Technique != TechniqueHiddenSingle {
+			t.Fatalf("expected only singles, got %s", step.Technique)
+		}
+	}
+	if !e.Solved() {
+		t.Fatalf("expected engine to solve the puzzle")
+	}
+}
+
+func TestTechniqueHinterReturnsHint(t *testing.T) {
+	t.Parallel()
+
+	_, grid, err := ParseAndNormalize(techniquePuzzles[0])
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	hint, err := TechniqueHinter{}.NextHint(grid)
+	if err != nil {
+		t.Fatalf("hint: %v", err)
+	}
+	if hint == nil {
+		t.Fatalf("expected a hint")
+	}
+	if len(hint.AffectedCells) == 0 || len(hint.Placements) == 0 {
+		t.Fatalf("expected affected cells and a placement, got %+v", hint)
+	}
+}
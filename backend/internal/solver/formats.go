@@ -0,0 +1,382 @@
+package solver
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// Format names a puzzle exchange format understood by ParsePuzzles.
+type Format string
+
+// Supported formats.
+const (
+	// FormatFlat is one 81-char puzzle per line, optionally followed by a title, which
+	// may start with '#'.
+	FormatFlat Format = "flat"
+	// FormatSDK is SadMan Software's .sdk: nine rows of nine cells, with '#' header
+	// lines ('#D' holds the description) and optional [Puzzle]/[State] sections.
+	FormatSDK Format = "sdk"
+	// FormatSimpleSudoku is Simple Sudoku's .ss: rows split into boxes by '|', with
+	// '-' lines between bands.
+	FormatSimpleSudoku Format = "ss"
+	// FormatOpenSudoku is the OpenSudoku XML collection, one <game data="..."> per puzzle.
+	FormatOpenSudoku Format = "opensudoku"
+	// FormatPencilmarks is a HoDoKu-style pencilmark grid: 81 whitespace-separated
+	// candidate sets, with any '|', '-', '+', ':', '.' and '*' borders ignored.
+	FormatPencilmarks Format = "pencilmarks"
+	// FormatCandidates is a SudokuWiki-style candidate string: 729 chars, nine per cell,
+	// with digit d at position d of the cell when it is a candidate and '0' or '.' when
+	// it is not.
+	FormatCandidates Format = "candidates"
+)
+
+// PuzzleEntry is one puzzle in an exchange format, as read by ParsePuzzles and written
+// by WritePuzzles. In the candidate formats a cell with a single candidate is a given.
+type PuzzleEntry struct {
+	Givens Grid
+	// Candidates holds the candidates of each empty cell (bit d for digit d), or is nil
+	// when the format has none.
+	Candidates *[81]uint16
+	// Title is the puzzle's name or description when the format carries one.
+	Title string
+}
+
+// ParsePuzzles reads every puzzle in input. An empty format is detected with
+// DetectFormat. It checks the layout only; givens may still conflict.
+func ParsePuzzles(input string, format Format) ([]PuzzleEntry, error) {
+	if format == "" {
+		detected, err := DetectFormat(input)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	var puzzles []PuzzleEntry
+	var err error
+	switch format {
+	case FormatFlat:
+		puzzles, err = parseFlat(input)
+	case FormatSDK, FormatSimpleSudoku:
+		puzzles, err = parseRows(input)
+	case FormatOpenSudoku:
+		puzzles, err = parseOpenSudoku(input)
+	case FormatPencilmarks:
+		puzzles, err = parsePencilmarks(input)
+	case FormatCandidates:
+		puzzles, err = parseCandidates(input)
+	default:
+		return nil, errors.New("unknown_format")
+	}
+	if err != nil {
+		return nil, errors.New("invalid_" + string(format))
+	}
+	if len(puzzles) == 0 {
+		return nil, errors.New("no_puzzles_found")
+	}
+	return puzzles, nil
+}
+
+// WritePuzzles writes puzzles in the flat, .sdk, .ss or OpenSudoku format, so that
+// ParsePuzzles reads them back. Only the flat, .sdk and OpenSudoku formats keep titles;
+// candidates are not written.
+func WritePuzzles(puzzles []PuzzleEntry, format Format) (string, error) {
+	var b strings.Builder
+	switch format {
+	case FormatFlat:
+		for _, p := range puzzles {
+			b.WriteString(p.Givens.String())
+			if title := oneLine(p.Title); title != "" {
+				b.WriteString(" #" + title)
+			}
+			b.WriteByte('\n')
+		}
+	case FormatSDK:
+		for i, p := range puzzles {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			if title := oneLine(p.Title); title != "" {
+				b.WriteString("#D" + title + "\n")
+			}
+			for r := 0; r < 9; r++ {
+				b.WriteString(rowCells(p.Givens, r) + "\n")
+			}
+		}
+	case FormatSimpleSudoku:
+		for i, p := range puzzles {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			for r := 0; r < 9; r++ {
+				if r == 3 || r == 6 {
+					b.WriteString("-----------\n")
+				}
+				row := rowCells(p.Givens, r)
+				b.WriteString(row[:3] + "|" + row[3:6] + "|" + row[6:] + "\n")
+			}
+		}
+	case FormatOpenSudoku:
+		type game struct {
+			Data string `xml:"data,attr"`
+			Note string `xml:"note,attr,omitempty"`
+		}
+		doc := struct {
+			XMLName xml.Name `xml:"opensudoku"`
+			Version string   `xml:"version,attr"`
+			Games   []game   `xml:"game"`
+		}{Version: "2"}
+		for _, p := range puzzles {
+			doc.Games = append(doc.Games, game{Data: p.Givens.String(), Note: oneLine(p.Title)})
+		}
+		out, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(xml.Header)
+		b.Write(out)
+		b.WriteByte('\n')
+	default:
+		return "", errors.New("unknown_format")
+	}
+	return b.String(), nil
+}
+
+// rowCells returns row r with '.' for empty cells.
+func rowCells(g Grid, r int) string {
+	row := []byte(g.String()[r*9 : r*9+9])
+	for i, ch := range row {
+		if ch == '0' {
+			row[i] = '.'
+		}
+	}
+	return string(row)
+}
+
+// oneLine collapses a title's whitespace, including newlines, to single spaces.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// DetectFormat guesses the format of input from its shape.
+func DetectFormat(input string) (Format, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return "", errors.New("no_puzzles_found")
+	}
+	if strings.HasPrefix(s, "<") {
+		return FormatOpenSudoku, nil
+	}
+	// Checked before flat: nine flat puzzles are also 729 cells, but put their digits
+	// anywhere.
+	if compact := strings.Join(strings.Fields(s), ""); len(compact) == 729 && isCandidateString(compact) {
+		return FormatCandidates, nil
+	}
+	if first := strings.Fields(strings.SplitN(s, "\n", 2)[0]); len(first) > 0 && len(first[0]) == 81 && isCells(first[0]) {
+		return FormatFlat, nil
+	}
+	if tokens := pencilmarkTokens(s); len(tokens) == 81 {
+		for _, t := range tokens {
+			if len(t) > 1 {
+				return FormatPencilmarks, nil
+			}
+		}
+	}
+	if strings.Contains(s, "|") {
+		return FormatSimpleSudoku, nil
+	}
+	return FormatSDK, nil
+}
+
+// isCells reports whether s holds only digits and '.' for empty cells.
+func isCells(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if ch := s[i]; ch != '.' && (ch < '0' || ch > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isCandidateString reports whether every char of s is empty or the digit its position
+// within the cell stands for.
+func isCandidateString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if ch := s[i]; ch != '0' && ch != '.' && ch != '1'+byte(i%9) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseFlat(input string) ([]PuzzleEntry, error) {
+	var puzzles []PuzzleEntry
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		_, g, err := ParseGrid(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		title := strings.TrimPrefix(strings.Join(fields[1:], " "), "#")
+		puzzles = append(puzzles, PuzzleEntry{Givens: g, Title: strings.TrimSpace(title)})
+	}
+	return puzzles, nil
+}
+
+// parseRows reads .sdk and .ss files: every line that is not a header or a border
+// holds one row of nine cells, and nine rows make a puzzle.
+func parseRows(input string) ([]PuzzleEntry, error) {
+	var (
+		puzzles []PuzzleEntry
+		current PuzzleEntry
+		rows    int
+		title   string
+		skip    bool
+	)
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#D"):
+			title = strings.TrimSpace(line[2:])
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			// SadMan saves the solving state after the puzzle; only [Puzzle] holds givens.
+			skip = !strings.EqualFold(line, "[Puzzle]")
+			continue
+		case skip:
+			continue
+		}
+
+		row := strings.Map(func(r rune) rune {
+			switch r {
+			case '|', '-', '+', '*', ' ', '\t', '\r':
+				return -1
+			case 'x', 'X':
+				return '.'
+			}
+			return r
+		}, line)
+		if row == "" {
+			continue
+		}
+		if len(row) != 9 || !isCells(row) {
+			return nil, errors.New("invalid_row")
+		}
+		for c := 0; c < 9; c++ {
+			if ch := row[c]; ch != '.' {
+				current.Givens[rows*9+c] = ch - '0'
+			}
+		}
+		rows++
+		if rows == 9 {
+			current.Title = title
+			puzzles = append(puzzles, current)
+			current, rows, title = PuzzleEntry{}, 0, ""
+		}
+	}
+	if rows != 0 {
+		return nil, errors.New("incomplete_grid")
+	}
+	return puzzles, nil
+}
+
+func parseOpenSudoku(input string) ([]PuzzleEntry, error) {
+	var doc struct {
+		Name  string `xml:"name"`
+		Games []struct {
+			Data string `xml:"data,attr"`
+			Note string `xml:"note,attr"`
+		} `xml:"game"`
+	}
+	if err := xml.Unmarshal([]byte(input), &doc); err != nil {
+		return nil, err
+	}
+	puzzles := make([]PuzzleEntry, 0, len(doc.Games))
+	for _, game := range doc.Games {
+		_, g, err := ParseGrid(game.Data)
+		if err != nil {
+			return nil, err
+		}
+		title := strings.TrimSpace(game.Note)
+		if title == "" {
+			title = strings.TrimSpace(doc.Name)
+		}
+		puzzles = append(puzzles, PuzzleEntry{Givens: g, Title: title})
+	}
+	return puzzles, nil
+}
+
+// pencilmarkTokens drops the borders of a pencilmark grid and returns its cells.
+func pencilmarkTokens(s string) []string {
+	return strings.Fields(strings.Map(func(r rune) rune {
+		switch r {
+		case '|', '-', '+', ':', '.', '*', ',', '\'':
+			return ' '
+		}
+		return r
+	}, s))
+}
+
+func parsePencilmarks(input string) ([]PuzzleEntry, error) {
+	tokens := pencilmarkTokens(input)
+	if len(tokens) != 81 {
+		return nil, errors.New("invalid_cell_count")
+	}
+	var cells [81]uint16
+	for i, t := range tokens {
+		for j := 0; j < len(t); j++ {
+			ch := t[j]
+			if ch < '1' || ch > '9' {
+				return nil, errors.New("invalid_candidate")
+			}
+			cells[i] |= uint16(1) << (ch - '0')
+		}
+	}
+	return []PuzzleEntry{fromCandidates(cells)}, nil
+}
+
+func parseCandidates(input string) ([]PuzzleEntry, error) {
+	s := strings.Join(strings.Fields(input), "")
+	if len(s) != 729 {
+		return nil, errors.New("invalid_length")
+	}
+	if !isCandidateString(s) {
+		return nil, errors.New("invalid_candidate")
+	}
+	var cells [81]uint16
+	for i := 0; i < 729; i++ {
+		if ch := s[i]; ch != '0' && ch != '.' {
+			cells[i/9] |= uint16(1) << (ch - '0')
+		}
+	}
+	for _, m := range cells {
+		if m == 0 {
+			return nil, errors.New("cell_without_candidates")
+		}
+	}
+	return []PuzzleEntry{fromCandidates(cells)}, nil
+}
+
+// fromCandidates turns single-candidate cells into givens and keeps the rest as
+// candidates.
+func fromCandidates(cells [81]uint16) PuzzleEntry {
+	p := PuzzleEntry{Candidates: new([81]uint16)}
+	for i, m := range cells {
+		if m&(m-1) == 0 {
+			for d := uint8(1); d <= 9; d++ {
+				if m == uint16(1)<<d {
+					p.Givens[i] = d
+				}
+			}
+			continue
+		}
+		p.Candidates[i] = m
+	}
+	return p
+}
@@ -0,0 +1,162 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+)
+
+const formatGivens = "530070000600195000098000060800060003400803001700020006060000280000419005000080079"
+
+func TestParsePuzzlesFormats(t *testing.T) {
+	t.Parallel()
+
+	_, want, _ := ParseGrid(formatGivens)
+	var rows, ssRows []string
+	for r := 0; r < 9; r++ {
+		row := strings.ReplaceAll(formatGivens[r*9:r*9+9], "0", ".")
+		rows = append(rows, row)
+		if r == 3 || r == 6 {
+			ssRows = append(ssRows, "---+---+---")
+		}
+		ssRows = append(ssRows, row[:3]+"|"+row[3:6]+"|"+row[6:])
+	}
+
+	cases := []struct {
+		name   string
+		format Format
+		input  string
+		title  string
+	}{
+		{"flat", FormatFlat, formatGivens + " Wikipedia example\n", "Wikipedia example"},
+		{"sdk", FormatSDK, "#AJohn Doe\n#DWikipedia example\n" + strings.Join(rows, "\r\n") + "\n[State]\n" + strings.Join(rows, "\n"), "Wikipedia example"},
+		{"ss", FormatSimpleSudoku, strings.Join(ssRows, "\n"), ""},
+		{"opensudoku", FormatOpenSudoku, `<?xml version="1.0" encoding="utf-8"?>
+<opensudoku version="2"><name>Easy</name><game data="` + formatGivens + `" /></opensudoku>`, "Easy"},
+	}
+	for _, tc := range cases {
+		puzzles, err := ParsePuzzles(tc.input, tc.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(puzzles) != 1 || puzzles[0].Givens != want || puzzles[0].Title != tc.title {
+			t.Fatalf("%s: got %+v", tc.name, puzzles)
+		}
+		if detected, _ := DetectFormat(tc.input); detected != tc.format {
+			t.Fatalf("%s: detected as %q", tc.name, detected)
+		}
+	}
+
+	if _, err := ParsePuzzles(strings.Join(rows[:8], "\n"), FormatSDK); err == nil || err.Error() != "invalid_sdk" {
+		t.Fatalf("expected invalid_sdk for eight rows, got %v", err)
+	}
+	if _, err := ParsePuzzles(formatGivens, "txt"); err == nil || err.Error() != "unknown_format" {
+		t.Fatalf("expected unknown_format, got %v", err)
+	}
+}
+
+func TestParsePuzzlesCandidates(t *testing.T) {
+	t.Parallel()
+
+	// Givens keep their digit; every empty cell gets the candidates left by its peers.
+	_, g, _ := ParseGrid(formatGivens)
+	e := NewEngine(g)
+	var cells [81]string
+	var wiki strings.Builder
+	for i := 0; i < 81; i++ {
+		mask := uint16(1) << g[i]
+		if g[i] == 0 {
+			mask = e.Candidates(i)
+		}
+		for d := 1; d <= 9; d++ {
+			if mask&(1<<d) != 0 {
+				cells[i] += string(rune('0' + d))
+				wiki.WriteByte(byte('0' + d))
+			} else {
+				wiki.WriteByte('0')
+			}
+		}
+	}
+	var hodoku strings.Builder
+	hodoku.WriteString(".-----------.-----------.-----------.\n")
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if c%3 == 0 {
+				hodoku.WriteString("| ")
+			}
+			hodoku.WriteString(cells[r*9+c] + " ")
+		}
+		hodoku.WriteString("|\n")
+		if r%3 == 2 {
+			hodoku.WriteString(":-----------+-----------+-----------:\n")
+		}
+	}
+
+	for _, tc := range []struct {
+		format Format
+		input  string
+	}{
+		{FormatPencilmarks, hodoku.String()},
+		{FormatCandidates, wiki.String()},
+	} {
+		if detected, _ := DetectFormat(tc.input); detected != tc.format {
+			t.Fatalf("%s: detected as %q", tc.format, detected)
+		}
+		puzzles, err := ParsePuzzles(tc.input, "")
+		if err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		p := puzzles[0]
+		if p.Candidates == nil {
+			t.Fatalf("%s: expected candidates", tc.format)
+		}
+		for i := 0; i < 81; i++ {
+			if g[i] != 0 && p.Givens[i] != g[i] {
+				t.Fatalf("%s: cell %d: expected given %d, got %d", tc.format, i, g[i], p.Givens[i])
+			}
+			if g[i] == 0 && p.Givens[i] == 0 && p.Candidates[i] != e.Candidates(i) {
+				t.Fatalf("%s: cell %d: candidates %b, want %b", tc.format, i, p.Candidates[i], e.Candidates(i))
+			}
+		}
+	}
+}
+
+func TestWritePuzzlesRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	var entries []PuzzleEntry
+	for i, p := range seventeenClue[:3] {
+		_, g, _ := ParseGrid(p)
+		entries = append(entries, PuzzleEntry{Givens: g, Title: []string{"", "Seventeen & <two>", "multi\nline"}[i]})
+	}
+	for _, format := range []Format{FormatFlat, FormatSDK, FormatSimpleSudoku, FormatOpenSudoku} {
+		out, err := WritePuzzles(entries, format)
+		if err != nil {
+			t.Fatalf("%s: write: %v", format, err)
+		}
+		if detected, _ := DetectFormat(out); detected != format {
+			t.Fatalf("%s: written output detected as %q:\n%s", format, detected, out)
+		}
+		parsed, err := ParsePuzzles(out, format)
+		if err != nil {
+			t.Fatalf("%s: parse: %v\n%s", format, err, out)
+		}
+		if len(parsed) != len(entries) {
+			t.Fatalf("%s: expected %d puzzles, got %d", format, len(entries), len(parsed))
+		}
+		for i, p := range parsed {
+			if p.Givens != entries[i].Givens {
+				t.Fatalf("%s: puzzle %d givens differ", format, i)
+			}
+			want := strings.Join(strings.Fields(entries[i].Title), " ")
+			if format == FormatSimpleSudoku {
+				want = ""
+			}
+			if p.Title != want {
+				t.Fatalf("%s: puzzle %d: expected title %q, got %q", format, i, want, p.Title)
+			}
+		}
+	}
+	if _, err := WritePuzzles(entries, FormatPencilmarks); err == nil {
+		t.Fatalf("expected an error for a format without a writer")
+	}
+}
@@ -0,0 +1,230 @@
+package solver
+
+import "errors"
+
+// Geometry constraint names, used in error codes such as "thermometer_conflict".
+const (
+	GeometryThermometer = "thermometer"
+	GeometryArrow       = "arrow"
+	GeometryPalindrome  = "palindrome"
+	GeometryWhisper     = "whisper"
+)
+
+// Geometry holds the lines and shapes drawn on a puzzle. Lines are cell indexes (0-80)
+// in order, each cell a king's move from the previous one.
+type Geometry struct {
+	// Thermometers start at the bulb; digits strictly increase along them.
+	Thermometers [][]int `json:"thermometers,omitempty"`
+	// Arrows: the circle's digit equals the sum of the digits along the path.
+	Arrows []Arrow `json:"arrows,omitempty"`
+	// Palindromes read the same in both directions.
+	Palindromes [][]int `json:"palindromes,omitempty"`
+	// Whispers are German whispers: neighbours on the line differ by at least 5.
+	Whispers [][]int `json:"whispers,omitempty"`
+}
+
+// Arrow is a circle cell and the path leading away from it.
+type Arrow struct {
+	Circle int   `json:"circle"`
+	Path   []int `json:"path"`
+}
+
+// IsEmpty reports whether the geometry draws nothing.
+func (geo Geometry) IsEmpty() bool {
+	return len(geo.Thermometers) == 0 && len(geo.Arrows) == 0 && len(geo.Palindromes) == 0 && len(geo.Whispers) == 0
+}
+
+// ValidateGeometry checks that every line is well formed. The givens are checked against
+// the lines by Variant.Validate.
+func ValidateGeometry(geo Geometry) error {
+	for _, line := range geo.Thermometers {
+		if len(line) < 2 || len(line) > 9 {
+			return errors.New("invalid_thermometer")
+		}
+		if err := validateLine(line); err != nil {
+			return err
+		}
+	}
+	for _, arrow := range geo.Arrows {
+		if len(arrow.Path) == 0 {
+			return errors.New("invalid_arrow")
+		}
+		if err := validateLine(arrow.cells()); err != nil {
+			return err
+		}
+	}
+	for _, line := range geo.Palindromes {
+		if len(line) < 2 {
+			return errors.New("invalid_palindrome")
+		}
+		if err := validateLine(line); err != nil {
+			return err
+		}
+	}
+	for _, line := range geo.Whispers {
+		if len(line) < 2 {
+			return errors.New("invalid_whisper")
+		}
+		if err := validateLine(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLine checks the cells of a line are on the grid, distinct and connected.
+func validateLine(line []int) error {
+	seen := map[int]bool{}
+	for k, idx := range line {
+		if idx < 0 || idx >= 81 {
+			return errors.New("invalid_geometry_cell")
+		}
+		if seen[idx] {
+			return errors.New("geometry_line_repeats_cell")
+		}
+		seen[idx] = true
+		if k > 0 {
+			prev := line[k-1]
+			if abs(prev/9-idx/9) > 1 || abs(prev%9-idx%9) > 1 {
+				return errors.New("geometry_line_not_connected")
+			}
+		}
+	}
+	return nil
+}
+
+// cells returns the circle followed by the path.
+func (a Arrow) cells() []int {
+	return append([]int{a.Circle}, a.Path...)
+}
+
+// constraints returns one constraint per kind of line in the geometry.
+func (geo Geometry) constraints() []Constraint {
+	var out []Constraint
+	if len(geo.Thermometers) > 0 {
+		out = append(out, newLineConstraint(GeometryThermometer, geo.Thermometers, thermometerAllowed))
+	}
+	if len(geo.Arrows) > 0 {
+		lines := make([][]int, len(geo.Arrows))
+		for k, a := range geo.Arrows {
+			lines[k] = a.cells()
+		}
+		out = append(out, newLineConstraint(GeometryArrow, lines, arrowAllowed))
+	}
+	if len(geo.Palindromes) > 0 {
+		out = append(out, newLineConstraint(GeometryPalindrome, geo.Palindromes, palindromeAllowed))
+	}
+	if len(geo.Whispers) > 0 {
+		out = append(out, newLineConstraint(GeometryWhisper, geo.Whispers, whisperAllowed))
+	}
+	return out
+}
+
+// lineConstraint applies a per-line rule to every line through a cell.
+type lineConstraint struct {
+	name    string
+	lines   [81][]lineRef
+	allowed func(g *Grid, line []int, pos int) uint16
+}
+
+// lineRef is a line and the position of a cell on it.
+type lineRef struct {
+	line []int
+	pos  int
+}
+
+func newLineConstraint(name string, lines [][]int, allowed func(g *Grid, line []int, pos int) uint16) *lineConstraint {
+	c := &lineConstraint{name: name, allowed: allowed}
+	for _, line := range lines {
+		for pos, idx := range line {
+			c.lines[idx] = append(c.lines[idx], lineRef{line: line, pos: pos})
+		}
+	}
+	return c
+}
+
+func (c *lineConstraint) Name() string { return c.name }
+
+func (c *lineConstraint) Allowed(g *Grid, idx int) uint16 {
+	out := allDigits
+	for _, ref := range c.lines[idx] {
+		out &= c.allowed(g, ref.line, ref.pos)
+	}
+	return out
+}
+
+// digitRange returns the digits from lo to hi.
+func digitRange(lo, hi int) uint16 {
+	var out uint16
+	for d := max(lo, 1); d <= min(hi, 9); d++ {
+		out |= uint16(1) << d
+	}
+	return out
+}
+
+// thermometerAllowed bounds a cell by its distance from the bulb and the tip, and by the
+// digits already placed along the thermometer.
+func thermometerAllowed(g *Grid, line []int, pos int) uint16 {
+	lo, hi := pos+1, 9-(len(line)-1-pos)
+	for k, idx := range line {
+		v := int(g[idx])
+		if v == 0 || k == pos {
+			continue
+		}
+		if k < pos {
+			lo = max(lo, v+pos-k)
+		} else {
+			hi = min(hi, v-(k-pos))
+		}
+	}
+	return digitRange(lo, hi)
+}
+
+// arrowAllowed keeps the circle (line[0]) equal to the sum of the path. Each empty path
+// cell adds 1 to 9 to the sum; the circle holds a single digit.
+func arrowAllowed(g *Grid, line []int, pos int) uint16 {
+	circle := int(g[line[0]])
+	sum, empty := 0, 0
+	for k, idx := range line[1:] {
+		if k+1 == pos {
+			continue
+		}
+		if v := int(g[idx]); v != 0 {
+			sum += v
+		} else {
+			empty++
+		}
+	}
+
+	if pos == 0 {
+		return digitRange(sum+empty, sum+9*empty)
+	}
+	if circle == 0 {
+		return digitRange(1, 9-sum-empty)
+	}
+	rest := circle - sum
+	return digitRange(rest-9*empty, rest-empty)
+}
+
+// palindromeAllowed matches a cell to its mirror on the line once that is placed.
+func palindromeAllowed(g *Grid, line []int, pos int) uint16 {
+	mirror := line[len(line)-1-pos]
+	if v := g[mirror]; v != 0 && mirror != line[pos] {
+		return uint16(1) << v
+	}
+	return allDigits
+}
+
+// whisperAllowed keeps neighbours on the line at least 5 apart, which rules out 5.
+func whisperAllowed(g *Grid, line []int, pos int) uint16 {
+	out := allDigits &^ (uint16(1) << 5)
+	for _, k := range [2]int{pos - 1, pos + 1} {
+		if k < 0 || k >= len(line) {
+			continue
+		}
+		if v := int(g[line[k]]); v != 0 {
+			out &= digitRange(1, v-5) | digitRange(v+5, 9)
+		}
+	}
+	return out
+}
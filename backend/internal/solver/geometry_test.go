@@ -0,0 +1,92 @@
+package solver
+
+import "testing"
+
+// ambiguousGivens has two solutions, which trade 3, 4 and 5 between R1C1-R1C3 and
+// R9C1-R9C3: R1 starts 345 in one and 534 in the other.
+const ambiguousGivens = "000070000600195000098000060800060003400803001700020006060000280000419005000080079"
+
+func TestGeometryResolvesAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	_, g, err := ParseAndNormalize(ambiguousGivens)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if n, _ := CountSolutions(g, 2); n != 2 {
+		t.Fatalf("expected two classic solutions, got %d", n)
+	}
+
+	tests := []struct {
+		name string
+		geo  Geometry
+		want string
+	}{
+		// 3 < 4 holds in the first solution, 5 < 3 fails in the second.
+		{"thermometer", Geometry{Thermometers: [][]int{{0, 1}}}, "345"},
+		// R2C2 is 7 = 4 + 3 in the first solution, not 3 + 5.
+		{"arrow", Geometry{Arrows: []Arrow{{Circle: 10, Path: []int{1, 0}}}}, "345"},
+		// R9C2 is 3 in the first solution, 5 away from the 8 in R8C2; the second has 4.
+		{"whisper", Geometry{Whispers: [][]int{{73, 64}}}, "345"},
+		// R1C2 must match the 3 in R3C4, as it does only in the second solution.
+		{"palindrome", Geometry{Palindromes: [][]int{{1, 11, 21}}}, "534"},
+	}
+	for _, tt := range tests {
+		v := Variant{Geometry: tt.geo}
+		solutions, err := v.FindSolutions(g, 2)
+		if err != nil {
+			t.Fatalf("%s: find: %v", tt.name, err)
+		}
+		if len(solutions) != 1 || solutions[0].String()[:3] != tt.want {
+			t.Fatalf("%s: expected the unique solution starting %s, got %v", tt.name, tt.want, solutions)
+		}
+	}
+}
+
+func TestGeometryConflictsAndShapes(t *testing.T) {
+	t.Parallel()
+
+	conflicts := []struct {
+		geo    Geometry
+		givens map[int]uint8
+		want   string
+	}{
+		{Geometry{Thermometers: [][]int{{0, 1, 2}}}, map[int]uint8{0: 5, 1: 3}, "thermometer_conflict"},
+		// The tip of a three-cell thermometer is at least 3.
+		{Geometry{Thermometers: [][]int{{0, 1, 2}}}, map[int]uint8{2: 2}, "thermometer_conflict"},
+		{Geometry{Arrows: []Arrow{{Circle: 0, Path: []int{1, 2}}}}, map[int]uint8{0: 1}, "arrow_conflict"},
+		{Geometry{Arrows: []Arrow{{Circle: 0, Path: []int{1, 2}}}}, map[int]uint8{0: 5, 1: 6}, "arrow_conflict"},
+		{Geometry{Palindromes: [][]int{{1, 11, 21}}}, map[int]uint8{1: 1, 21: 2}, "palindrome_conflict"},
+		{Geometry{Whispers: [][]int{{0, 1}}}, map[int]uint8{0: 3, 1: 6}, "whisper_conflict"},
+		{Geometry{Whispers: [][]int{{0, 1}}}, map[int]uint8{0: 5}, "whisper_conflict"},
+	}
+	for _, tt := range conflicts {
+		var g Grid
+		for idx, d := range tt.givens {
+			g[idx] = d
+		}
+		v := Variant{Geometry: tt.geo}
+		if err := v.Validate(g); err == nil || err.Error() != tt.want {
+			t.Fatalf("%v: expected %s, got %v", tt.givens, tt.want, err)
+		}
+		if n, _ := v.CountSolutions(g, 1); n != 0 {
+			t.Fatalf("%v: expected no solutions, got %d", tt.givens, n)
+		}
+	}
+
+	shapes := []struct {
+		geo  Geometry
+		want string
+	}{
+		{Geometry{Thermometers: [][]int{{0}}}, "invalid_thermometer"},
+		{Geometry{Arrows: []Arrow{{Circle: 0}}}, "invalid_arrow"},
+		{Geometry{Whispers: [][]int{{80, 81}}}, "invalid_geometry_cell"},
+		{Geometry{Palindromes: [][]int{{0, 1, 0}}}, "geometry_line_repeats_cell"},
+		{Geometry{Whispers: [][]int{{0, 2}}}, "geometry_line_not_connected"},
+	}
+	for _, tt := range shapes {
+		if _, err := (Variant{Geometry: tt.geo}).CountSolutions(Grid{}, 1); err == nil || err.Error() != tt.want {
+			t.Fatalf("expected %s, got %v", tt.want, err)
+		}
+	}
+}
@@ -12,6 +12,19 @@ type Grid [81]uint8
 
 // ParseAndNormalize parses a string representation of a Sudoku puzzle and returns a normalized string and grid.
 func ParseAndNormalize(input string) (string, Grid, error) {
+	normalized, g, err := ParseGrid(input)
+	if err != nil {
+		return "", Grid{}, err
+	}
+	if err := ValidateNoConflicts(g); err != nil {
+		return "", Grid{}, err
+	}
+	return normalized, g, nil
+}
+
+// ParseGrid is ParseAndNormalize without the classic conflict check, for variants whose
+// units differ (see Variant.Validate).
+func ParseGrid(input string) (string, Grid, error) {
 	s := strings.TrimSpace(input)
 	s = strings.ReplaceAll(s, "\n", "")
 	s = strings.ReplaceAll(s, "\r", "")
@@ -39,13 +52,18 @@ func ParseAndNormalize(input string) (string, Grid, error) {
 		}
 	}
 
-	if err := ValidateNoConflicts(g); err != nil {
-		return "", Grid{}, err
-	}
-
 	return b.String(), g, nil
 }
 
+// String returns the grid as an 81-char string with '0' for empty cells.
+func (g Grid) String() string {
+	b := make([]byte, 81)
+	for i, v := range g {
+		b[i] = '0' + v
+	}
+	return string(b)
+}
+
 // ValidateNoConflicts checks that a grid has no conflicts in rows, columns, or boxes.
 func ValidateNoConflicts(g Grid) error {
 	for r := 0; r < 9; r++ {
@@ -100,3 +118,17 @@ func ValidateNoConflicts(g Grid) error {
 	return nil
 }
 
+// FindConflict returns two filled cells that hold the same digit and see each other.
+func FindConflict(g Grid) (a, b int, ok bool) {
+	for i := 0; i < 81; i++ {
+		if g[i] == 0 {
+			continue
+		}
+		for j := i + 1; j < 81; j++ {
+			if g[j] == g[i] && sees(i, j) {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -1,9 +1,17 @@
 package solver
 
-// TechniqueID identifies a solving technique.
-// Technique-based hinting is intentionally separate from the backtracking solver.
-// The backtracking solver enforces "unique solution" today; a future technique engine can implement Hinter.
-type TechniqueID string
+import "fmt"
+
+// HintLevel controls how much of a hint is revealed, from a gentle nudge to the full answer.
+type HintLevel int
+
+// Hint levels, each revealing everything the previous one did.
+const (
+	HintLevelRegion    HintLevel = 1 // "Look at box 5"
+	HintLevelTechnique HintLevel = 2 // names the technique
+	HintLevelCells     HintLevel = 3 // highlights the cells involved
+	HintLevelFull      HintLevel = 4 // gives the placement or eliminations
+)
 
 // AffectedCell represents a cell affected by a hint.
 type AffectedCell struct {
@@ -13,22 +21,120 @@ type AffectedCell struct {
 
 // Hint provides information about a solving technique that can be applied.
 type Hint struct {
-	Technique      TechniqueID    `json:"technique"`
+	Level          HintLevel      `json:"level"`
+	Region         string         `json:"region"`
+	Technique      TechniqueID    `json:"technique,omitempty"`
+	Difficulty     int            `json:"difficulty,omitempty"`
 	Message        string         `json:"message"`
 	AffectedCells  []AffectedCell `json:"affectedCells,omitempty"`
 	HighlightedRow *int           `json:"highlightedRow,omitempty"`
 	HighlightedCol *int           `json:"highlightedCol,omitempty"`
+	Placements     []Placement    `json:"placements,omitempty"`
+	Eliminations   []Elimination  `json:"eliminations,omitempty"`
 }
 
 // Hinter provides hints for solving puzzles using human-style techniques.
+// Technique-based hinting is intentionally separate from the backtracking solver,
+// which only enforces "unique solution".
 type Hinter interface {
 	NextHint(grid Grid) (*Hint, error)
 }
 
-// NotImplementedHinter is a stub implementation that returns no hints.
-type NotImplementedHinter struct{}
+// TechniqueHinter implements Hinter with the technique engine.
+type TechniqueHinter struct{}
+
+// NextHint returns the easiest logical step for the grid, or nil when no technique applies.
+func (h TechniqueHinter) NextHint(grid Grid) (*Hint, error) {
+	if err := ValidateNoConflicts(grid); err != nil {
+		return nil, err
+	}
+	step := NewEngine(grid).FindStep()
+	if step == nil {
+		return nil, nil
+	}
+	return step.Hint(), nil
+}
+
+// Hint converts the step to a full (level 4) Hint. A row or column is highlighted when every
+// affected cell lies in it.
+func (s *Step) Hint() *Hint {
+	h := &Hint{
+		Level:        HintLevelFull,
+		Region:       unitName(18 + boxOf(s.target())),
+		Technique:    s.Technique,
+		Difficulty:   s.Difficulty,
+		Message:      s.Message,
+		Placements:   s.Placements,
+		Eliminations: s.Eliminations,
+	}
+
+	seen := map[int]bool{}
+	for _, idx := range s.Cells {
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		h.AffectedCells = append(h.AffectedCells, AffectedCell{Row: idx / 9, Col: idx % 9})
+	}
+
+	if len(h.AffectedCells) > 1 {
+		row, col := h.AffectedCells[0].Row, h.AffectedCells[0].Col
+		sameRow, sameCol := true, true
+		for _, c := range h.AffectedCells[1:] {
+			sameRow = sameRow && c.Row == row
+			sameCol = sameCol && c.Col == col
+		}
+		if sameRow {
+			h.HighlightedRow = &row
+		}
+		if sameCol {
+			h.HighlightedCol = &col
+		}
+	}
+
+	return h
+}
+
+// AtLevel returns a copy of the hint that reveals only what the given level allows.
+func (h *Hint) AtLevel(level HintLevel) *Hint {
+	if level < HintLevelRegion {
+		level = HintLevelRegion
+	}
+	if level >= HintLevelFull {
+		out := *h
+		out.Level = HintLevelFull
+		return &out
+	}
+
+	out := &Hint{Level: level, Region: h.Region}
+	switch level {
+	case HintLevelRegion:
+		out.Message = fmt.Sprintf("Look at %s", h.Region)
+	case HintLevelTechnique:
+		out.Technique = h.Technique
+		out.Difficulty = h.Difficulty
+		out.Message = fmt.Sprintf("Look at %s and use the %s technique", h.Region, h.Technique.Name())
+	case HintLevelCells:
+		out.Technique = h.Technique
+		out.Difficulty = h.Difficulty
+		out.AffectedCells = h.AffectedCells
+		out.HighlightedRow = h.HighlightedRow
+		out.HighlightedCol = h.HighlightedCol
+		out.Message = fmt.Sprintf("Use the %s technique on the highlighted cells", h.Technique.Name())
+	}
+	return out
+}
 
-// NextHint returns nil, indicating no hints are available.
-func (h NotImplementedHinter) NextHint(_ Grid) (*Hint, error) {
-	return nil, nil
+// target is the cell the step changes first: its placement, or else its first elimination.
+func (s *Step) target() int {
+	if len(s.Placements) > 0 {
+		return s.Placements[0].Cell
+	}
+	if len(s.Eliminations) > 0 {
+		return s.Eliminations[0].Cell
+	}
+	if len(s.Cells) > 0 {
+		return s.Cells[0]
+	}
+	return 0
 }
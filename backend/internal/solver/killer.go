@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// Cage is a Killer Sudoku cage: its cells hold distinct digits that add up to Sum.
+type Cage struct {
+	Cells []int `json:"cells"`
+	Sum   int   `json:"sum"`
+}
+
+// ValidateCages checks that cages are well formed, do not overlap, and that the givens
+// inside them neither repeat a digit nor exceed the cage sum.
+func ValidateCages(g Grid, cages []Cage) error {
+	var used [81]bool
+	for _, cage := range cages {
+		n := len(cage.Cells)
+		if n == 0 || n > 9 {
+			return errors.New("invalid_cage_size")
+		}
+		if cage.Sum < minCageSum(allDigits, n) || cage.Sum > maxCageSum(allDigits, n) {
+			return errors.New("invalid_cage_sum")
+		}
+
+		var seen uint16
+		sum := 0
+		for _, idx := range cage.Cells {
+			if idx < 0 || idx >= 81 {
+				return errors.New("invalid_cage_cell")
+			}
+			if used[idx] {
+				return errors.New("overlapping_cages")
+			}
+			used[idx] = true
+
+			if v := g[idx]; v != 0 {
+				bit := uint16(1) << v
+				if seen&bit != 0 {
+					return errors.New("cage_conflict")
+				}
+				seen |= bit
+				sum += int(v)
+			}
+		}
+		if sum > cage.Sum {
+			return errors.New("cage_sum_exceeded")
+		}
+	}
+	return nil
+}
+
+// cageIndex is the Constraint for Killer cages; it maps cells to their cage.
+type cageIndex struct {
+	cages  []Cage
+	cageOf [81]int
+}
+
+func newCageIndex(cages []Cage) *cageIndex {
+	ci := &cageIndex{cages: cages}
+	for i := range ci.cageOf {
+		ci.cageOf[i] = -1
+	}
+	for k, cage := range cages {
+		for _, idx := range cage.Cells {
+			ci.cageOf[idx] = k
+		}
+	}
+	return ci
+}
+
+func (ci *cageIndex) Name() string { return "cage" }
+
+// Allowed returns the digits idx may take without repeating inside its cage or making
+// the cage sum unreachable.
+func (ci *cageIndex) Allowed(g *Grid, idx int) uint16 {
+	if ci.cageOf[idx] < 0 {
+		return allDigits
+	}
+	cage := ci.cages[ci.cageOf[idx]]
+
+	var used uint16
+	remaining := cage.Sum
+	empty := 0
+	for _, c := range cage.Cells {
+		if v := g[c]; v != 0 {
+			used |= uint16(1) << v
+			remaining -= int(v)
+		} else {
+			empty++
+		}
+	}
+
+	free := allDigits &^ used
+	var out uint16
+	for d := 1; d <= 9; d++ {
+		bit := uint16(1) << d
+		if free&bit == 0 {
+			continue
+		}
+		rest := remaining - d
+		others := empty - 1
+		avail := free &^ bit
+		if others == 0 {
+			if rest == 0 {
+				out |= bit
+			}
+			continue
+		}
+		if rest >= minCageSum(avail, others) && rest <= maxCageSum(avail, others) {
+			out |= bit
+		}
+	}
+	return out
+}
+
+// minCageSum is the smallest sum of n distinct digits from mask, or a huge value if
+// mask has fewer than n digits.
+func minCageSum(mask uint16, n int) int {
+	if bits.OnesCount16(mask) < n {
+		return 1 << 30
+	}
+	sum := 0
+	for d := 1; d <= 9 && n > 0; d++ {
+		if mask&(uint16(1)<<d) != 0 {
+			sum += d
+			n--
+		}
+	}
+	return sum
+}
+
+// maxCageSum is the largest sum of n distinct digits from mask, or -1 if mask has fewer
+// than n digits.
+func maxCageSum(mask uint16, n int) int {
+	if bits.OnesCount16(mask) < n {
+		return -1
+	}
+	sum := 0
+	for d := 9; d >= 1 && n > 0; d-- {
+		if mask&(uint16(1)<<d) != 0 {
+			sum += d
+			n--
+		}
+	}
+	return sum
+}
@@ -0,0 +1,119 @@
+package solver
+
+import "testing"
+
+func TestCountSolutionsWithCagesEnforcesSums(t *testing.T) {
+	t.Parallel()
+
+	_, solved, err := ParseAndNormalize("534678912672195348198342567859761423426853791713924856961537284287419635345286179")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	// Cover the whole grid with horizontal dominoes (plus the last column as singles).
+	var cages []Cage
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 8; c += 2 {
+			a, b := r*9+c, r*9+c+1
+			cages = append(cages, Cage{Cells: []int{a, b}, Sum: int(solved[a] + solved[b])})
+		}
+		cages = append(cages, Cage{Cells: []int{r*9 + 8}, Sum: int(solved[r*9+8])})
+	}
+
+	var empty Grid
+	solutions, err := Variant{Cages: cages}.FindSolutions(empty, 2)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(solutions) == 0 {
+		t.Fatalf("expected at least one solution")
+	}
+	for _, s := range solutions {
+		if err := ValidateNoConflicts(s); err != nil {
+			t.Fatalf("invalid solution: %v", err)
+		}
+		for _, cage := range cages {
+			sum := 0
+			for _, idx := range cage.Cells {
+				sum += int(s[idx])
+			}
+			if sum != cage.Sum {
+				t.Fatalf("cage %v sums to %d, want %d", cage.Cells, sum, cage.Sum)
+			}
+		}
+	}
+}
+
+func TestCagesBreakAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	_, g, err := ParseAndNormalize("000070000600195000098000060800060003400803001700020006060000280000419005000080079")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	a, err := FindAmbiguity(g)
+	if err != nil || a == nil {
+		t.Fatalf("expected an ambiguous puzzle")
+	}
+
+	// A single-cell cage acts like a given taken from the first solution.
+	d := a.DiffCells[0]
+	cage := Cage{Cells: []int{d}, Sum: int(a.Solutions[0][d])}
+	solutions, err := Variant{Cages: []Cage{cage}}.FindSolutions(g, 10)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	found := false
+	for _, s := range solutions {
+		if s == a.Solutions[1] {
+			t.Fatalf("expected the second solution to be ruled out")
+		}
+		if s == a.Solutions[0] {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the caged solution to remain")
+	}
+}
+
+func TestValidateCages(t *testing.T) {
+	t.Parallel()
+
+	var g Grid
+	g[0], g[1] = 4, 4
+	tests := []struct {
+		cages []Cage
+		want  string
+	}{
+		{[]Cage{{Cells: []int{0, 1}, Sum: 8}}, "cage_conflict"},
+		{[]Cage{{Cells: []int{2, 3}, Sum: 2}}, "invalid_cage_sum"},
+		{[]Cage{{Cells: []int{2, 3}, Sum: 5}, {Cells: []int{3, 4}, Sum: 5}}, "overlapping_cages"},
+		{[]Cage{{Cells: []int{81}, Sum: 5}}, "invalid_cage_cell"},
+		{[]Cage{{Cells: nil, Sum: 5}}, "invalid_cage_size"},
+		{[]Cage{{Cells: []int{0, 9}, Sum: 3}}, "cage_sum_exceeded"},
+	}
+	for _, tt := range tests {
+		err := ValidateCages(g, tt.cages)
+		if err == nil || err.Error() != tt.want {
+			t.Fatalf("cages %v: expected %s, got %v", tt.cages, tt.want, err)
+		}
+	}
+	if err := ValidateCages(g, []Cage{{Cells: []int{0, 9}, Sum: 12}}); err != nil {
+		t.Fatalf("expected valid cage, got %v", err)
+	}
+}
+
+func TestOffGridCageCellsAreRejected(t *testing.T) {
+	t.Parallel()
+
+	for _, cells := range [][]int{{81, 0}, {-1, 0}} {
+		v := Variant{Cages: []Cage{{Cells: cells, Sum: 10}}}
+		if _, err := v.CountSolutions(Grid{}, 2); err == nil || err.Error() != "invalid_cage_cell" {
+			t.Fatalf("count with cells %v: expected invalid_cage_cell, got %v", cells, err)
+		}
+		if _, err := v.FindSolutions(Grid{}, 2); err == nil || err.Error() != "invalid_cage_cell" {
+			t.Fatalf("find with cells %v: expected invalid_cage_cell, got %v", cells, err)
+		}
+	}
+}
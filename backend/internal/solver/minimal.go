@@ -0,0 +1,62 @@
+package solver
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotUnique is returned by the minimality checks for puzzles without exactly one solution.
+var ErrNotUnique = errors.New("puzzle_must_have_unique_solution")
+
+// RedundantGivens returns the givens that can each be removed on their own without losing
+// uniqueness. Removing several of them at once may still make the puzzle ambiguous.
+func RedundantGivens(g Grid) ([]int, error) {
+	return Variant{}.RedundantGivens(g)
+}
+
+// IsMinimal reports whether no given can be removed without losing uniqueness.
+func IsMinimal(g Grid) (bool, error) {
+	return Variant{}.IsMinimal(g)
+}
+
+// RedundantGivens is RedundantGivens under the variant's rules.
+func (v Variant) RedundantGivens(g Grid) ([]int, error) {
+	return v.RedundantGivensContext(context.Background(), g)
+}
+
+// RedundantGivensContext is RedundantGivens, stopping with ErrTimeout once ctx is done.
+func (v Variant) RedundantGivensContext(ctx context.Context, g Grid) ([]int, error) {
+	count, err := v.CountSolutionsContext(ctx, g, 2)
+	if err != nil {
+		return nil, err
+	}
+	if count != 1 {
+		return nil, ErrNotUnique
+	}
+
+	var out []int
+	for i := 0; i < 81; i++ {
+		if g[i] == 0 {
+			continue
+		}
+		candidate := g
+		candidate[i] = 0
+		count, err := v.CountSolutionsContext(ctx, candidate, 2)
+		if err != nil {
+			return nil, err
+		}
+		if count == 1 {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+// IsMinimal is IsMinimal under the variant's rules.
+func (v Variant) IsMinimal(g Grid) (bool, error) {
+	redundant, err := v.RedundantGivens(g)
+	if err != nil {
+		return false, err
+	}
+	return len(redundant) == 0, nil
+}
@@ -0,0 +1,126 @@
+package solver
+
+import (
+	"errors"
+	"strings"
+)
+
+// layout lists the units (rows, columns, then boxes or jigsaw regions) whose cells must
+// hold distinct digits, and the units of each cell.
+type layout struct {
+	units     *[27][9]int
+	cellUnits *[81][3]int
+}
+
+// classicLayout has the nine rows, columns and 3x3 boxes.
+var classicLayout = layout{units: &units, cellUnits: &cellUnits}
+
+// NormalizeRegions checks a jigsaw region map: 81 chars using nine distinct symbols,
+// each covering nine orthogonally connected cells. Symbols are renumbered '1'-'9' in
+// order of first appearance, so "AAABBB..." and "111222..." normalize the same way.
+func NormalizeRegions(raw string) (string, error) {
+	s := strings.Join(strings.Fields(raw), "")
+	if len(s) != 81 {
+		return "", errors.New("regions_must_be_81_chars")
+	}
+
+	ids := map[byte]byte{}
+	out := make([]byte, 81)
+	var sizes [9]int
+	for i := 0; i < 81; i++ {
+		id, ok := ids[s[i]]
+		if !ok {
+			if len(ids) == 9 {
+				return "", errors.New("regions_must_be_nine")
+			}
+			id = byte('1' + len(ids))
+			ids[s[i]] = id
+		}
+		out[i] = id
+		sizes[id-'1']++
+	}
+	for _, n := range sizes {
+		if n != 9 {
+			return "", errors.New("region_must_have_9_cells")
+		}
+	}
+
+	for id := byte('1'); id <= '9'; id++ {
+		if !regionConnected(out, id) {
+			return "", errors.New("region_not_connected")
+		}
+	}
+	return string(out), nil
+}
+
+// regionConnected flood-fills from the first cell of a region.
+func regionConnected(regions []byte, id byte) bool {
+	start := strings.IndexByte(string(regions), id)
+	seen := map[int]bool{start: true}
+	stack := []int{start}
+	for len(stack) > 0 {
+		idx := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		r, c := idx/9, idx%9
+		for _, n := range [4][2]int{{r - 1, c}, {r + 1, c}, {r, c - 1}, {r, c + 1}} {
+			if n[0] < 0 || n[0] > 8 || n[1] < 0 || n[1] > 8 {
+				continue
+			}
+			next := n[0]*9 + n[1]
+			if regions[next] == id && !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return len(seen) == 9
+}
+
+// newRegionLayout builds the layout for a normalized region map.
+func newRegionLayout(regions string) *layout {
+	l := &layout{units: new([27][9]int), cellUnits: new([81][3]int)}
+	var filled [9]int
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			l.units[i][j] = i*9 + j
+			l.units[9+i][j] = j*9 + i
+		}
+	}
+	for idx := 0; idx < 81; idx++ {
+		region := int(regions[idx] - '1')
+		l.units[18+region][filled[region]] = idx
+		filled[region]++
+	}
+	for u := 0; u < 27; u++ {
+		for _, idx := range l.units[u] {
+			l.cellUnits[idx][u/9] = u
+		}
+	}
+	return l
+}
+
+// conflict reports two equal digits in one unit, naming the kind of unit.
+func (l *layout) conflict(g Grid, regionKind string) error {
+	for u := 0; u < 27; u++ {
+		var seen uint16
+		for _, idx := range l.units[u] {
+			v := g[idx]
+			if v == 0 {
+				continue
+			}
+			bit := uint16(1) << v
+			if seen&bit != 0 {
+				switch u / 9 {
+				case 0:
+					return errors.New("row_conflict")
+				case 1:
+					return errors.New("col_conflict")
+				default:
+					return errors.New(regionKind + "_conflict")
+				}
+			}
+			seen |= bit
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package solver
+
+import "testing"
+
+// jigsawRegions is the classic box layout with boxes 1 and 2 trading a border cell.
+const jigsawRegions = "111122333" +
+	"111222333" +
+	"112222333" +
+	"444555666" +
+	"444555666" +
+	"444555666" +
+	"777888999" +
+	"777888999" +
+	"777888999"
+
+func TestNormalizeRegions(t *testing.T) {
+	t.Parallel()
+
+	letters := "AAAABBCCC" +
+		"AAABBBCCC" +
+		"AABBBBCCC" +
+		"DDDEEEFFF" +
+		"DDDEEEFFF" +
+		"DDDEEEFFF" +
+		"GGGHHHIII" +
+		"GGGHHHIII" +
+		"GGGHHHIII"
+	got, err := NormalizeRegions(letters)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if got != jigsawRegions {
+		t.Fatalf("expected letters to normalize to digits, got %s", got)
+	}
+
+	tests := []struct {
+		regions string
+		want    string
+	}{
+		{jigsawRegions[:80], "regions_must_be_81_chars"},
+		// Region 1 gains a cell from region 2.
+		{"111112333" + jigsawRegions[9:], "region_must_have_9_cells"},
+		// Regions 1 and 5 swap cells, leaving each with an isolated cell.
+		{"511122333" + jigsawRegions[9:36] + "444515666" + jigsawRegions[45:], "region_not_connected"},
+	}
+	for _, tt := range tests {
+		if _, err := NormalizeRegions(tt.regions); err == nil || err.Error() != tt.want {
+			t.Fatalf("%s: expected %s, got %v", tt.regions, tt.want, err)
+		}
+	}
+}
+
+func TestJigsawSolvesAgainstRegions(t *testing.T) {
+	t.Parallel()
+
+	// A classic solution is also a jigsaw solution when the two traded cells, R1C4 and
+	// R3C3, hold the same digit.
+	var seed Grid
+	seed[3], seed[20] = 1, 1
+	classic, err := FindSolutions(seed, 1)
+	if err != nil || len(classic) != 1 {
+		t.Fatalf("expected a classic solution, got %v", err)
+	}
+	solution := classic[0]
+
+	v := Variant{Regions: jigsawRegions}
+	if err := v.Validate(solution); err != nil {
+		t.Fatalf("solution breaks the regions: %v", err)
+	}
+
+	// Carve a few givens and check uniqueness is judged against the regions.
+	g := solution
+	for i := 0; i < 81; i += 2 {
+		candidate := g
+		candidate[i] = 0
+		if n, _ := v.CountSolutions(candidate, 2); n == 1 {
+			g = candidate
+		}
+	}
+	solutions, err := v.FindSolutions(g, 2)
+	if err != nil || len(solutions) != 1 || solutions[0] != solution {
+		t.Fatalf("expected the carved jigsaw to keep its unique solution")
+	}
+
+	// R1C4 joins region 1 and R3C3 joins region 2, so these pairs clash only as a jigsaw.
+	for _, pair := range [][2]int{{9, 3}, {20, 12}} {
+		var clash Grid
+		clash[pair[0]], clash[pair[1]] = 5, 5
+		if err := ValidateNoConflicts(clash); err != nil {
+			t.Fatalf("cells %v: unexpected classic conflict %v", pair, err)
+		}
+		if err := v.Validate(clash); err == nil || err.Error() != "region_conflict" {
+			t.Fatalf("cells %v: expected region_conflict, got %v", pair, err)
+		}
+	}
+	// R1C1 and R3C3 share a classic box but not a region.
+	var shared Grid
+	shared[0], shared[20] = 5, 5
+	if err := v.Validate(shared); err != nil {
+		t.Fatalf("expected no jigsaw conflict, got %v", err)
+	}
+}
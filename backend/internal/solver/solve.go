@@ -1,29 +1,86 @@
 package solver
 
 import (
+	"context"
 	"errors"
 )
 
-// CountSolutions counts the number of solutions for a Sudoku puzzle up to the given limit.
-func CountSolutions(g Grid, limit int) (int, error) {
+// Solver counts and lists the solutions of a classic Sudoku. BitboardSolver is the
+// default behind CountSolutions; BacktrackingSolver is the generic search that variants
+// use, DLXSolver an exact-cover alternative, and ParallelSolver the bitboard search
+// spread over a worker pool.
+type Solver interface {
+	CountSolutions(g Grid, limit int) (int, error)
+	FindSolutions(g Grid, limit int) ([]Grid, error)
+}
+
+// BacktrackingSolver implements Solver with the bitmask search Variant uses for rules
+// beyond classic Sudoku.
+type BacktrackingSolver struct{}
+
+// CountSolutions counts the solutions of a classic Sudoku up to limit.
+func (BacktrackingSolver) CountSolutions(g Grid, limit int) (int, error) {
 	if limit <= 0 {
 		return 0, errors.New("invalid_limit")
 	}
-	if err := ValidateNoConflicts(g); err != nil {
+	if ValidateNoConflicts(g) != nil {
 		return 0, nil
 	}
+	return search(context.Background(), g, &classicLayout, nil, limit, nil)
+}
+
+// FindSolutions returns up to limit solutions of a classic Sudoku.
+func (BacktrackingSolver) FindSolutions(g Grid, limit int) ([]Grid, error) {
+	if limit <= 0 {
+		return nil, errors.New("invalid_limit")
+	}
+	if ValidateNoConflicts(g) != nil {
+		return nil, nil
+	}
+	var solutions []Grid
+	_, err := search(context.Background(), g, &classicLayout, nil, limit, func(solution Grid) {
+		solutions = append(solutions, solution)
+	})
+	return solutions, err
+}
+
+// CountSolutions counts the number of solutions for a Sudoku puzzle up to the given limit.
+func CountSolutions(g Grid, limit int) (int, error) {
+	return Variant{}.CountSolutions(g, limit)
+}
+
+// FindSolutions returns up to limit solutions of a Sudoku puzzle.
+func FindSolutions(g Grid, limit int) ([]Grid, error) {
+	return Variant{}.FindSolutions(g, limit)
+}
+
+// search runs the backtracking DFS and calls visit (if non-nil) for each solution found.
+// Digits used per unit are tracked incrementally; other rules come from constraints.
+// It stops with ErrTimeout once ctx is done.
+func search(ctx context.Context, g Grid, lay *layout, constraints []Constraint, limit int, visit func(Grid)) (int, error) {
+	var used [27]uint16
+	for i := 0; i < 81; i++ {
+		if v := g[i]; v != 0 {
+			for _, u := range lay.cellUnits[i] {
+				used[u] |= uint16(1) << v
+			}
+		}
+	}
 
-	usedRows, usedCols, usedBoxes := buildUsedMasks(g)
 	count := 0
-	var dfs func(Grid, [9]uint16, [9]uint16, [9]uint16)
-	dfs = func(grid Grid, rows, cols, boxes [9]uint16) {
-		if count >= limit {
+	stop := deadline{ctx: ctx}
+	var dfs func(Grid, [27]uint16)
+	dfs = func(grid Grid, used [27]uint16) {
+		if count >= limit || stop.expired() {
 			return
 		}
 
-		idx, candidates := pickNextCell(grid, rows, cols, boxes)
+		idx, candidates := pickNextCell(&grid, lay, &used, constraints)
 		if idx == -1 {
 			count++
+			if visit != nil {
+				visit(grid)
+			}
 			return
 		}
 		if candidates == 0 {
@@ -36,29 +93,22 @@ func CountSolutions(g Grid, limit int) (int, error) {
 				continue
 			}
 
-			r := idx / 9
-			c := idx % 9
-			b := (r/3)*3 + (c / 3)
-
 			grid2 := grid
 			grid2[idx] = digit
+			used2 := used
+			for _, u := range lay.cellUnits[idx] {
+				used2[u] |= bit
+			}
 
-			rows2 := rows
-			cols2 := cols
-			boxes2 := boxes
-			rows2[r] |= bit
-			cols2[c] |= bit
-			boxes2[b] |= bit
-
-			dfs(grid2, rows2, cols2, boxes2)
-			if count >= limit {
+			dfs(grid2, used2)
+			if count >= limit || stop.err != nil {
 				return
 			}
 		}
 	}
 
-	dfs(g, usedRows, usedCols, usedBoxes)
-	return count, nil
+	dfs(g, used)
+	return count, stop.err
 }
 
 func buildUsedMasks(g Grid) (rows, cols, boxes [9]uint16) {
@@ -78,7 +128,8 @@ func buildUsedMasks(g Grid) (rows, cols, boxes [9]uint16) {
 	return rows, cols, boxes
 }
 
-func pickNextCell(g Grid, rows, cols, boxes [9]uint16) (idx int, candidates uint16) {
+// pickNextCell returns the empty cell with the fewest candidates, or -1 when the grid is full.
+func pickNextCell(g *Grid, lay *layout, used *[27]uint16, constraints []Constraint) (idx int, candidates uint16) {
 	bestIdx := -1
 	bestCount := 10
 	var bestCandidates uint16
@@ -88,11 +139,11 @@ func pickNextCell(g Grid, rows, cols, boxes [9]uint16) (idx int, candidates uint
 			continue
 		}
 
-		r := i / 9
-		c := i % 9
-		b := (r/3)*3 + (c / 3)
-		used := rows[r] | cols[c] | boxes[b]
-		cands := (^used) & 0x3FE
+		cu := &lay.cellUnits[i]
+		cands := allDigits &^ (used[cu[0]] | used[cu[1]] | used[cu[2]])
+		for _, c := range constraints {
+			cands &= c.Allowed(g, i)
+		}
 		candCount := bitsCount16(cands)
 		if candCount == 0 {
 			return i, 0
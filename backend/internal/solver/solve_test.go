@@ -78,3 +78,102 @@ func TestCountSolutionsConflict(t *testing.T) {
 	_ = grid
 }
 
+func TestFindSolutionsReturnsDistinctSolutions(t *testing.T) {
+	t.Parallel()
+
+	var empty Grid
+	solutions, err := FindSolutions(empty, 2)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(solutions) != 2 {
+		t.Fatalf("expected 2 solutions, got %d", len(solutions))
+	}
+	if solutions[0] == solutions[1] {
+		t.Fatalf("expected distinct solutions")
+	}
+	for _, s := range solutions {
+		if err := ValidateNoConflicts(s); err != nil {
+			t.Fatalf("invalid solution: %v", err)
+		}
+	}
+}
+
+func TestFindAmbiguitySuggestsResolvingGiven(t *testing.T) {
+	t.Parallel()
+
+	_, g, err := ParseAndNormalize("530070000600195000098000060800060003400803001700020006060000280000419005000080079")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if a, _ := FindAmbiguity(g); a != nil {
+		t.Fatalf("expected no ambiguity for a unique puzzle")
+	}
+
+	// Drop givens until the puzzle stops being unique.
+	for i := 0; i < 81; i++ {
+		if g[i] == 0 {
+			continue
+		}
+		g[i] = 0
+		if count, _ := CountSolutions(g, 2); count > 1 {
+			break
+		}
+	}
+
+	a, err := FindAmbiguity(g)
+	if err != nil || a == nil {
+		t.Fatalf("expected an ambiguity, got %v, %v", a, err)
+	}
+	if len(a.DiffCells) == 0 {
+		t.Fatalf("expected differing cells")
+	}
+	for _, idx := range a.DiffCells {
+		if a.Solutions[0][idx] == a.Solutions[1][idx] || g[idx] != 0 {
+			t.Fatalf("cell %d is not a differing empty cell", idx)
+		}
+	}
+	if !a.Resolves {
+		t.Fatalf("expected a resolving suggestion")
+	}
+	g[a.SuggestedCell] = a.SuggestedDigit
+	if count, _ := CountSolutions(g, 2); count != 1 {
+		t.Fatalf("expected the suggestion to make the puzzle unique, got %d solutions", count)
+	}
+}
+
+func TestRedundantGivensUntilMinimal(t *testing.T) {
+	t.Parallel()
+
+	_, g, err := ParseAndNormalize("530070000600195000098000060800060003400803001700020006060000280000419005000080079")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	for {
+		redundant, err := RedundantGivens(g)
+		if err != nil {
+			t.Fatalf("redundant: %v", err)
+		}
+		if len(redundant) == 0 {
+			break
+		}
+		for _, idx := range redundant {
+			candidate := g
+			candidate[idx] = 0
+			if count, _ := CountSolutions(candidate, 2); count != 1 {
+				t.Fatalf("removing redundant given %d lost uniqueness", idx)
+			}
+		}
+		g[redundant[0]] = 0
+	}
+
+	if minimal, err := IsMinimal(g); err != nil || !minimal {
+		t.Fatalf("expected a minimal puzzle, got %v, %v", minimal, err)
+	}
+
+	var empty Grid
+	if _, err := RedundantGivens(empty); err != ErrNotUnique {
+		t.Fatalf("expected ErrNotUnique, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+)
+
+// seventeenClue are minimal 17-clue puzzles, followed by two well-known hard ones
+// (AI Escargot and Arto Inkala's 2012 puzzle).
+var seventeenClue = []string{
+	"000000010400000000020000000000050407008000300001090000300400200050100000000806000",
+	"000000012000035000000600070700000300000400800100000000000120000080000040050000600",
+	"000000012003600000000007000410020000000500300700000600280000040000300500000000000",
+	"000000012008030000000000040120500000000004700060000000507000300000620000000100000",
+	"000000012040050000000009000070600400000100000000000050000087500601000300200000000",
+	"000000012050400000000000030700600400001000000000080000920000800000510700000003000",
+	"000000012300000060000040000900000500000001070020000000000350400001400800060000000",
+}
+
+var hardPuzzles = append(append([]string{}, seventeenClue...),
+	"100007090030020008009600500005300900010080002600004000300000010040000007007000300",
+	"800000000003600000070090200050007000000045700000100030001000068008500010090000400",
+)
+
+var solvers = []struct {
+	name   string
+	solver Solver
+}{
+	{"backtracking", BacktrackingSolver{}},
+	{"dlx", DLXSolver{}},
+	{"bitboard", BitboardSolver{}},
+	{"parallel", ParallelSolver{Workers: 4}},
+}
+
+func TestSolversAgree(t *testing.T) {
+	t.Parallel()
+
+	_, conflict, _ := ParseGrid("11" + ambiguousGivens[2:])
+	cases := append(append([]string{}, hardPuzzles...), ambiguousGivens,
+		"534678912672195348198342567859761423426853791713924856961537284287419635345286179")
+	for _, p := range cases {
+		_, g, err := ParseAndNormalize(p)
+		if err != nil {
+			t.Fatalf("parse %s: %v", p, err)
+		}
+		want, _ := BacktrackingSolver{}.FindSolutions(g, 2)
+
+		for _, s := range solvers[1:] {
+			got, err := s.solver.FindSolutions(g, 2)
+			if err != nil {
+				t.Fatalf("%s: find %s: %v", s.name, p, err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("%s: %s: expected %d solutions, got %d", s.name, p, len(want), len(got))
+			}
+			for i := range got {
+				if err := ValidateNoConflicts(got[i]); err != nil {
+					t.Fatalf("%s: %s: invalid solution: %v", s.name, p, err)
+				}
+				for idx, v := range g {
+					if v != 0 && got[i][idx] != v {
+						t.Fatalf("%s: %s: solution overwrites a given", s.name, p)
+					}
+				}
+			}
+			if len(want) == 1 && got[0] != want[0] {
+				t.Fatalf("%s: %s: solutions differ", s.name, p)
+			}
+		}
+	}
+
+	// Exact counts on a grid with many solutions exercise each solver's backtracking.
+	_, sparse, _ := ParseGrid(ambiguousGivens[:63] + "000000000000000000")
+	want, _ := BacktrackingSolver{}.CountSolutions(sparse, 5000)
+	if want != 2400 {
+		t.Fatalf("expected 2400 solutions, got %d", want)
+	}
+	for _, s := range solvers[1:] {
+		if n, _ := s.solver.CountSolutions(sparse, 5000); n != want {
+			t.Fatalf("%s: expected %d solutions, got %d", s.name, want, n)
+		}
+	}
+
+	for _, s := range solvers {
+		if n, err := s.solver.CountSolutions(conflict, 2); err != nil || n != 0 {
+			t.Fatalf("%s: expected no solutions for conflicting givens, got %d, %v", s.name, n, err)
+		}
+		if _, err := s.solver.CountSolutions(Grid{}, 0); err == nil {
+			t.Fatalf("%s: expected an error for a zero limit", s.name)
+		}
+		if n, _ := s.solver.CountSolutions(Grid{}, 5); n != 5 {
+			t.Fatalf("%s: expected the empty grid to hit the limit, got %d", s.name, n)
+		}
+	}
+}
+
+func TestParallelSolverFindsDistinctSolutions(t *testing.T) {
+	t.Parallel()
+
+	_, sparse, _ := ParseGrid(ambiguousGivens[:63] + "000000000000000000")
+	for _, limit := range []int{1, 7, 2400} {
+		solutions, err := ParallelSolver{Workers: 3}.FindSolutions(sparse, limit)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if len(solutions) != limit {
+			t.Fatalf("expected %d solutions, got %d", limit, len(solutions))
+		}
+		seen := make(map[Grid]bool)
+		for _, g := range solutions {
+			if seen[g] {
+				t.Fatalf("solution found twice: %s", g)
+			}
+			seen[g] = true
+		}
+	}
+}
+
+func BenchmarkCountManySolutions(b *testing.B) {
+	_, sparse, _ := ParseGrid(ambiguousGivens[:54] + strings.Repeat("0", 27))
+	for _, s := range solvers[1:] {
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if n, _ := s.solver.CountSolutions(sparse, 100000); n != 100000 {
+					b.Fatalf("expected to reach the limit, got %d", n)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCountSolutions(b *testing.B) {
+	sets := []struct {
+		name    string
+		puzzles []string
+	}{
+		{"17clue", seventeenClue},
+		{"hard", hardPuzzles[len(seventeenClue):]},
+	}
+	for _, set := range sets {
+		grids := make([]Grid, len(set.puzzles))
+		for i, p := range set.puzzles {
+			_, grids[i], _ = ParseAndNormalize(p)
+		}
+		for _, s := range solvers {
+			b.Run(set.name+"/"+s.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					for _, g := range grids {
+						if n, _ := s.solver.CountSolutions(g, 2); n != 1 {
+							b.Fatalf("expected a unique solution, got %d", n)
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package solver
+
+// TechniqueID identifies a solving technique.
+// The IDs and difficulties match the frontend TypeScript solver so both sides grade alike.
+type TechniqueID string
+
+// Technique IDs, grouped by difficulty (1-10).
+const (
+	TechniqueNakedSingle      TechniqueID = "naked_single"
+	TechniqueHiddenSingle     TechniqueID = "hidden_single"
+	TechniquePointingPair     TechniqueID = "pointing_pair"
+	TechniqueBoxLineReduction TechniqueID = "box_line_reduction"
+	TechniqueHiddenPair       TechniqueID = "hidden_pair"
+	TechniqueNakedPair        TechniqueID = "naked_pair"
+	TechniqueHiddenTriple     TechniqueID = "hidden_triple"
+	TechniqueNakedTriple      TechniqueID = "naked_triple"
+	TechniqueHiddenQuad       TechniqueID = "hidden_quad"
+	TechniqueXWing            TechniqueID = "x_wing"
+	TechniqueNakedQuad        TechniqueID = "naked_quad"
+	TechniqueSwordfish        TechniqueID = "swordfish"
+	TechniqueJellyfish        TechniqueID = "jellyfish"
+	TechniqueXYWing           TechniqueID = "xy_wing"
+	TechniqueWWing            TechniqueID = "w_wing"
+	TechniqueXYZWing          TechniqueID = "xyz_wing"
+	TechniqueSkyscraper       TechniqueID = "skyscraper"
+	TechniqueTwoStringKite    TechniqueID = "two_string_kite"
+	TechniqueTurbotFish       TechniqueID = "turbot_fish"
+	TechniqueSimpleColoring   TechniqueID = "simple_coloring"
+	TechniqueXYChain          TechniqueID = "xy_chain"
+)
+
+var techniqueNames = map[TechniqueID]string{
+	TechniqueNakedSingle:      "Naked Single",
+	TechniqueHiddenSingle:     "Hidden Single",
+	TechniquePointingPair:     "Pointing Pair",
+	TechniqueBoxLineReduction: "Box/Line Reduction",
+	TechniqueHiddenPair:       "Hidden Pair",
+	TechniqueNakedPair:        "Naked Pair",
+	TechniqueHiddenTriple:     "Hidden Triple",
+	TechniqueNakedTriple:      "Naked Triple",
+	TechniqueHiddenQuad:       "Hidden Quad",
+	TechniqueXWing:            "X-Wing",
+	TechniqueNakedQuad:        "Naked Quad",
+	TechniqueSwordfish:        "Swordfish",
+	TechniqueJellyfish:        "Jellyfish",
+	TechniqueXYWing:           "XY-Wing",
+	TechniqueWWing:            "W-Wing",
+	TechniqueXYZWing:          "XYZ-Wing",
+	TechniqueSkyscraper:       "Skyscraper",
+	TechniqueTwoStringKite:    "Two-String Kite",
+	TechniqueTurbotFish:       "Turbot Fish",
+	TechniqueSimpleColoring:   "Simple Coloring",
+	TechniqueXYChain:          "XY-Chain",
+}
+
+// Name returns the human-readable technique name.
+func (id TechniqueID) Name() string {
+	if name, ok := techniqueNames[id]; ok {
+		return name
+	}
+	return string(id)
+}
+
+// Placement is a digit placed into a cell by a solving step.
+type Placement struct {
+	Cell  int   `json:"cell"`
+	Digit uint8 `json:"digit"`
+}
+
+// Elimination is a candidate digit removed from a cell by a solving step.
+type Elimination struct {
+	Cell  int   `json:"cell"`
+	Digit uint8 `json:"digit"`
+}
+
+// Step is a single logical deduction made by the technique engine.
+type Step struct {
+	Technique    TechniqueID   `json:"technique"`
+	Difficulty   int           `json:"difficulty"`
+	Message      string        `json:"message"`
+	Cells        []int         `json:"cells"`
+	Placements   []Placement   `json:"placements,omitempty"`
+	Eliminations []Elimination `json:"eliminations,omitempty"`
+}
+
+// Technique is a named deduction rule. find returns nil when the rule makes no progress.
+type Technique struct {
+	ID   TechniqueID
+	find func(e *Engine) *Step
+}
+
+// DefaultTechniques lists the techniques in the order the engine tries them.
+// Uniqueness-based techniques (BUG, unique rectangles) are left out on purpose: the engine
+// is also used to grade puzzles whose uniqueness has not been established yet.
+var DefaultTechniques = []Technique{
+	{ID: TechniqueHiddenSingle, find: findHiddenSingle},
+	{ID: TechniqueNakedSingle, find: findNakedSingle},
+	{ID: TechniquePointingPair, find: findPointing},
+	{ID: TechniqueBoxLineReduction, find: findBoxLineReduction},
+	{ID: TechniqueHiddenPair, find: hiddenSubsetFinder(2)},
+	{ID: TechniqueNakedPair, find: nakedSubsetFinder(2)},
+	{ID: TechniqueHiddenTriple, find: hiddenSubsetFinder(3)},
+	{ID: TechniqueNakedTriple, find: nakedSubsetFinder(3)},
+	{ID: TechniqueHiddenQuad, find: hiddenSubsetFinder(4)},
+	{ID: TechniqueXWing, find: fishFinder(2)},
+	{ID: TechniqueNakedQuad, find: nakedSubsetFinder(4)},
+	{ID: TechniqueSwordfish, find: fishFinder(3)},
+	{ID: TechniqueJellyfish, find: fishFinder(4)},
+	{ID: TechniqueXYWing, find: findXYWing},
+	{ID: TechniqueWWing, find: findWWing},
+	{ID: TechniqueXYZWing, find: findXYZWing},
+	{ID: TechniqueTurbotFish, find: findTurbotFish},
+	{ID: TechniqueSimpleColoring, find: findSimpleColoring},
+	{ID: TechniqueXYChain, find: findXYChain},
+}
@@ -0,0 +1,257 @@
+package solver
+
+import "fmt"
+
+const maxXYChainLength = 10
+
+// strongLink is a conjugate pair: the only two cells of a unit that can hold a digit.
+type strongLink struct {
+	a, b int
+	unit int
+}
+
+func (e *Engine) strongLinks(d uint8) []strongLink {
+	var out []strongLink
+	for u := 0; u < 27; u++ {
+		if cells := e.cellsWith(u, d); len(cells) == 2 {
+			out = append(out, strongLink{a: cells[0], b: cells[1], unit: u})
+		}
+	}
+	return out
+}
+
+// findTurbotFish finds two strong links on a digit whose inner ends see each other;
+// one of the outer ends must then hold the digit. Skyscrapers (two parallel lines) and
+// two-string kites (a row and a column meeting in a box) are reported under their own names.
+func findTurbotFish(e *Engine) *Step {
+	for d := uint8(1); d <= 9; d++ {
+		links := e.strongLinks(d)
+		for i := range links {
+			for j := i + 1; j < len(links); j++ {
+				l1, l2 := links[i], links[j]
+				if l1.a == l2.a || l1.a == l2.b || l1.b == l2.a || l1.b == l2.b {
+					continue
+				}
+				for _, p := range [][4]int{
+					{l1.a, l1.b, l2.a, l2.b},
+					{l1.a, l1.b, l2.b, l2.a},
+					{l1.b, l1.a, l2.a, l2.b},
+					{l1.b, l1.a, l2.b, l2.a},
+				} {
+					end1, mid1, mid2, end2 := p[0], p[1], p[2], p[3]
+					if !sees(mid1, mid2) {
+						continue
+					}
+					chain := []int{end1, mid1, mid2, end2}
+					elims := e.eliminationsFrom(commonPeers(end1, end2), d, func(idx int) bool {
+						return idx == mid1 || idx == mid2
+					})
+					if len(elims) == 0 {
+						continue
+					}
+					id, name := TechniqueTurbotFish, "Turbot Fish"
+					switch {
+					case l1.unit < 18 && l2.unit < 18 && l1.unit/9 == l2.unit/9:
+						id, name = TechniqueSkyscraper, "Skyscraper"
+					case l1.unit < 18 && l2.unit < 18 && boxOf(mid1) == boxOf(mid2):
+						id, name = TechniqueTwoStringKite, "Two-String Kite"
+					}
+					return &Step{
+						Technique:  id,
+						Difficulty: 9,
+						Message: fmt.Sprintf("%s on digit %d (%s and %s): one of %s or %s is %d",
+							name, d, unitName(l1.unit), unitName(l2.unit), cellName(end1), cellName(end2), d),
+						Cells:        append(chain, elimCells(elims)...),
+						Eliminations: elims,
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findSimpleColoring two-colors the strong-link graph of a digit. Two cells of one color
+// seeing each other rule that color out (color wrap); a cell seeing both colors cannot
+// hold the digit (color trap).
+func findSimpleColoring(e *Engine) *Step {
+	for d := uint8(1); d <= 9; d++ {
+		links := e.strongLinks(d)
+		adj := map[int][]int{}
+		for _, l := range links {
+			adj[l.a] = append(adj[l.a], l.b)
+			adj[l.b] = append(adj[l.b], l.a)
+		}
+
+		color := map[int]int{}
+		for start := 0; start < 81; start++ {
+			if _, ok := adj[start]; !ok {
+				continue
+			}
+			if _, done := color[start]; done {
+				continue
+			}
+
+			// Breadth-first 2-coloring of one connected component.
+			color[start] = 0
+			component := []int{start}
+			consistent := true
+			for q := 0; q < len(component); q++ {
+				cur := component[q]
+				for _, next := range adj[cur] {
+					c, seen := color[next]
+					if !seen {
+						color[next] = 1 - color[cur]
+						component = append(component, next)
+					} else if c == color[cur] {
+						consistent = false
+					}
+				}
+			}
+			if !consistent || len(component) < 3 {
+				continue
+			}
+
+			if step := e.colorWrap(d, component, color); step != nil {
+				return step
+			}
+			if step := e.colorTrap(d, component, color); step != nil {
+				return step
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Engine) colorWrap(d uint8, component []int, color map[int]int) *Step {
+	for i, a := range component {
+		for _, b := range component[i+1:] {
+			if color[a] != color[b] || !sees(a, b) {
+				continue
+			}
+			var elims []Elimination
+			for _, idx := range component {
+				if color[idx] == color[a] {
+					elims = append(elims, Elimination{Cell: idx, Digit: d})
+				}
+			}
+			return &Step{
+				Technique:  TechniqueSimpleColoring,
+				Difficulty: 10,
+				Message: fmt.Sprintf("Simple Coloring on digit %d: %s and %s share a color and see each other, so that color is false",
+					d, cellName(a), cellName(b)),
+				Cells:        append([]int{}, component...),
+				Eliminations: elims,
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Engine) colorTrap(d uint8, component []int, color map[int]int) *Step {
+	inComponent := map[int]bool{}
+	for _, idx := range component {
+		inComponent[idx] = true
+	}
+	bit := uint16(1) << d
+	var elims []Elimination
+	for idx := 0; idx < 81; idx++ {
+		if inComponent[idx] || e.candidates[idx]&bit == 0 {
+			continue
+		}
+		var seesColor [2]bool
+		for _, c := range component {
+			if sees(idx, c) {
+				seesColor[color[c]] = true
+			}
+		}
+		if seesColor[0] && seesColor[1] {
+			elims = append(elims, Elimination{Cell: idx, Digit: d})
+		}
+	}
+	if len(elims) == 0 {
+		return nil
+	}
+	return &Step{
+		Technique:    TechniqueSimpleColoring,
+		Difficulty:   10,
+		Message:      fmt.Sprintf("Simple Coloring on digit %d: cells seeing both colors cannot be %d", d, d),
+		Cells:        append(append([]int{}, component...), elimCells(elims)...),
+		Eliminations: elims,
+	}
+}
+
+// findXYChain follows bivalue cells where each cell forces the next. If the chain starts
+// and ends on the same digit, that digit is removed from cells seeing both ends.
+// Chains are searched breadth-first over (cell, digit) states so the shortest chain wins.
+func findXYChain(e *Engine) *Step {
+	type state struct {
+		cell  int
+		digit uint8
+	}
+	for start := 0; start < 81; start++ {
+		m := e.candidates[start]
+		if bitsCount16(m) != 2 {
+			continue
+		}
+		ds := digitsOf(m)
+		for i, target := range ds {
+			first := state{cell: start, digit: ds[1-i]}
+			parent := map[state]state{first: first}
+			depth := map[state]int{first: 0}
+			queue := []state{first}
+			for q := 0; q < len(queue); q++ {
+				cur := queue[q]
+				if depth[cur] >= maxXYChainLength-1 {
+					continue
+				}
+				bit := uint16(1) << cur.digit
+				for _, next := range peers[cur.cell] {
+					nm := e.candidates[next]
+					if next == start || bitsCount16(nm) != 2 || nm&bit == 0 {
+						continue
+					}
+					ns := state{cell: next, digit: digitsOf(nm &^ bit)[0]}
+					if _, seen := parent[ns]; seen {
+						continue
+					}
+					parent[ns] = cur
+					depth[ns] = depth[cur] + 1
+					queue = append(queue, ns)
+
+					if ns.digit != target || depth[ns] < 2 {
+						continue
+					}
+					var path []int
+					for s := ns; ; s = parent[s] {
+						path = append([]int{s.cell}, path...)
+						if s == first {
+							break
+						}
+					}
+					inPath := func(idx int) bool {
+						for _, c := range path {
+							if c == idx {
+								return true
+							}
+						}
+						return false
+					}
+					elims := e.eliminationsFrom(commonPeers(start, next), target, inPath)
+					if len(elims) == 0 {
+						continue
+					}
+					return &Step{
+						Technique:  TechniqueXYChain,
+						Difficulty: 10,
+						Message: fmt.Sprintf("XY-Chain %s: either %s or %s is %d",
+							joinCells(path), cellName(start), cellName(next), target),
+						Cells:        append(path, elimCells(elims)...),
+						Eliminations: elims,
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package solver
+
+import "fmt"
+
+var fishSizes = map[int]struct {
+	id         TechniqueID
+	name       string
+	difficulty int
+}{
+	2: {TechniqueXWing, "X-Wing", 6},
+	3: {TechniqueSwordfish, "Swordfish", 7},
+	4: {TechniqueJellyfish, "Jellyfish", 7},
+}
+
+// fishFinder finds n rows (or columns) whose candidates for a digit lie in exactly n
+// columns (or rows); the digit is then removed from the rest of those cover lines.
+func fishFinder(n int) func(*Engine) *Step {
+	meta := fishSizes[n]
+	return func(e *Engine) *Step {
+		for d := uint8(1); d <= 9; d++ {
+			for kind := 0; kind < 2; kind++ {
+				if step := e.findFish(n, d, kind, meta.id, meta.name, meta.difficulty); step != nil {
+					return step
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// findFish searches base lines of the given kind (0 rows, 1 columns) for a fish of size n.
+func (e *Engine) findFish(n int, d uint8, kind int, id TechniqueID, name string, difficulty int) *Step {
+	coverKind := 1 - kind
+	var pool []int
+	var where [27][]int
+	for line := kind * 9; line < kind*9+9; line++ {
+		cells := e.cellsWith(line, d)
+		if len(cells) >= 2 && len(cells) <= n {
+			pool = append(pool, line)
+			where[line] = cells
+		}
+	}
+	if len(pool) < n {
+		return nil
+	}
+
+	var step *Step
+	combinations(pool, n, func(bases []int) bool {
+		var covers []int
+		var cells []int
+		for _, b := range bases {
+			for _, idx := range where[b] {
+				cells = append(cells, idx)
+				c := cellUnits[idx][coverKind]
+				found := false
+				for _, existing := range covers {
+					if existing == c {
+						found = true
+						break
+					}
+				}
+				if !found {
+					covers = append(covers, c)
+				}
+			}
+		}
+		if len(covers) != n {
+			return false
+		}
+		inBase := func(idx int) bool {
+			for _, b := range bases {
+				if cellUnits[idx][kind] == b {
+					return true
+				}
+			}
+			return false
+		}
+		var elims []Elimination
+		for _, c := range covers {
+			elims = append(elims, e.eliminationsFrom(units[c][:], d, inBase)...)
+		}
+		if len(elims) == 0 {
+			return false
+		}
+		step = &Step{
+			Technique:    id,
+			Difficulty:   difficulty,
+			Message:      fmt.Sprintf("%s on digit %d in %s, eliminating it from the rest of %s", name, d, joinUnits(bases), joinUnits(covers)),
+			Cells:        append(cells, elimCells(elims)...),
+			Eliminations: elims,
+		}
+		return true
+	})
+	return step
+}
+
+func joinUnits(us []int) string {
+	out := ""
+	for i, u := range us {
+		switch {
+		case i == 0:
+		case i == len(us)-1:
+			out += " and "
+		default:
+			out += ", "
+		}
+		out += unitName(u)
+	}
+	return out
+}
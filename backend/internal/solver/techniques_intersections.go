@@ -0,0 +1,97 @@
+package solver
+
+import "fmt"
+
+// findPointing finds a digit confined to one row or column inside a box.
+func findPointing(e *Engine) *Step {
+	for box := 18; box < 27; box++ {
+		for d := uint8(1); d <= 9; d++ {
+			cells := e.cellsWith(box, d)
+			if len(cells) < 2 || len(cells) > 3 {
+				continue
+			}
+			for kind := 0; kind < 2; kind++ {
+				line, ok := sharedUnit(cells, kind)
+				if !ok {
+					continue
+				}
+				elims := e.eliminationsFrom(units[line][:], d, func(idx int) bool {
+					return cellUnits[idx][2] == box
+				})
+				if len(elims) == 0 {
+					continue
+				}
+				return &Step{
+					Technique:  TechniquePointingPair,
+					Difficulty: 2,
+					Message: fmt.Sprintf("In %s, digit %d appears only in %s, eliminating it from the rest of the %s (Pointing Pair)",
+						unitName(box), d, unitName(line), unitKind(line)),
+					Cells:        append(append([]int{}, cells...), elimCells(elims)...),
+					Eliminations: elims,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findBoxLineReduction finds a digit confined to one box inside a row or column.
+func findBoxLineReduction(e *Engine) *Step {
+	for line := 0; line < 18; line++ {
+		for d := uint8(1); d <= 9; d++ {
+			cells := e.cellsWith(line, d)
+			if len(cells) < 2 || len(cells) > 3 {
+				continue
+			}
+			box, ok := sharedUnit(cells, 2)
+			if !ok {
+				continue
+			}
+			elims := e.eliminationsFrom(units[box][:], d, func(idx int) bool {
+				return cellUnits[idx][line/9] == line
+			})
+			if len(elims) == 0 {
+				continue
+			}
+			return &Step{
+				Technique:  TechniqueBoxLineReduction,
+				Difficulty: 2,
+				Message: fmt.Sprintf("In %s, digit %d appears only in %s, eliminating it from the rest of the box (Box/Line Reduction)",
+					unitName(line), d, unitName(box)),
+				Cells:        append(append([]int{}, cells...), elimCells(elims)...),
+				Eliminations: elims,
+			}
+		}
+	}
+	return nil
+}
+
+// sharedUnit returns the unit of the given kind (0 row, 1 column, 2 box) shared by all cells.
+func sharedUnit(cells []int, kind int) (int, bool) {
+	u := cellUnits[cells[0]][kind]
+	for _, idx := range cells[1:] {
+		if cellUnits[idx][kind] != u {
+			return 0, false
+		}
+	}
+	return u, true
+}
+
+func unitKind(u int) string {
+	switch u / 9 {
+	case 0:
+		return "row"
+	case 1:
+		return "column"
+	default:
+		return "box"
+	}
+}
+
+func elimCells(elims []Elimination) []int {
+	out := make([]int, 0, len(elims))
+	for _, el := range elims {
+		out = append(out, el.Cell)
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package solver
+
+import "fmt"
+
+// hiddenSingleUnitOrder scans boxes first, since box singles are the easiest to spot.
+var hiddenSingleUnitOrder = func() []int {
+	order := make([]int, 0, 27)
+	for u := 18; u < 27; u++ {
+		order = append(order, u)
+	}
+	for u := 0; u < 18; u++ {
+		order = append(order, u)
+	}
+	return order
+}()
+
+func findHiddenSingle(e *Engine) *Step {
+	for _, u := range hiddenSingleUnitOrder {
+		for d := uint8(1); d <= 9; d++ {
+			cells := e.cellsWith(u, d)
+			if len(cells) != 1 {
+				continue
+			}
+			// Row and column hidden singles are harder to spot than box ones.
+			difficulty := 3
+			if u >= 18 {
+				difficulty = 1
+			}
+			idx := cells[0]
+			return &Step{
+				Technique:  TechniqueHiddenSingle,
+				Difficulty: difficulty,
+				Message:    fmt.Sprintf("In %s, digit %d can only go in cell %s (Hidden Single)", unitName(u), d, cellName(idx)),
+				Cells:      []int{idx},
+				Placements: []Placement{{Cell: idx, Digit: d}},
+			}
+		}
+	}
+	return nil
+}
+
+func findNakedSingle(e *Engine) *Step {
+	for idx := 0; idx < 81; idx++ {
+		if e.values[idx] != 0 || bitsCount16(e.candidates[idx]) != 1 {
+			continue
+		}
+		d := digitsOf(e.candidates[idx])[0]
+
+		// Difficulty scales with how crowded the cell's busiest unit still is.
+		maxEmpty := 0
+		for _, u := range cellUnits[idx] {
+			empty := 0
+			for _, other := range units[u] {
+				if e.values[other] == 0 {
+					empty++
+				}
+			}
+			if empty > maxEmpty {
+				maxEmpty = empty
+			}
+		}
+		difficulty := 3
+		switch {
+		case maxEmpty == 1:
+			difficulty = 1
+		case maxEmpty <= 3:
+			difficulty = 2
+		}
+
+		return &Step{
+			Technique:  TechniqueNakedSingle,
+			Difficulty: difficulty,
+			Message:    fmt.Sprintf("Cell %s can only contain %d (Naked Single)", cellName(idx), d),
+			Cells:      []int{idx},
+			Placements: []Placement{{Cell: idx, Digit: d}},
+		}
+	}
+	return nil
+}
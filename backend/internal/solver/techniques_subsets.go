@@ -0,0 +1,161 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+)
+
+var subsetNames = map[int]string{2: "Pair", 3: "Triple", 4: "Quad"}
+
+var nakedSubsets = map[int]struct {
+	id         TechniqueID
+	difficulty int
+}{
+	2: {TechniqueNakedPair, 4},
+	3: {TechniqueNakedTriple, 5},
+	4: {TechniqueNakedQuad, 6},
+}
+
+var hiddenSubsets = map[int]struct {
+	id         TechniqueID
+	difficulty int
+}{
+	2: {TechniqueHiddenPair, 3},
+	3: {TechniqueHiddenTriple, 4},
+	4: {TechniqueHiddenQuad, 5},
+}
+
+// nakedSubsetFinder finds n cells in a unit whose candidates span exactly n digits.
+func nakedSubsetFinder(n int) func(*Engine) *Step {
+	meta := nakedSubsets[n]
+	return func(e *Engine) *Step {
+		for u := 0; u < 27; u++ {
+			var pool []int
+			for _, idx := range units[u] {
+				if cnt := bitsCount16(e.candidates[idx]); e.values[idx] == 0 && cnt >= 2 && cnt <= n {
+					pool = append(pool, idx)
+				}
+			}
+			if len(pool) < n {
+				continue
+			}
+
+			var step *Step
+			combinations(pool, n, func(cells []int) bool {
+				var union uint16
+				for _, idx := range cells {
+					union |= e.candidates[idx]
+				}
+				if bitsCount16(union) != n {
+					return false
+				}
+				inSubset := func(idx int) bool {
+					for _, c := range cells {
+						if c == idx {
+							return true
+						}
+					}
+					return false
+				}
+				var elims []Elimination
+				for _, d := range digitsOf(union) {
+					elims = append(elims, e.eliminationsFrom(units[u][:], d, inSubset)...)
+				}
+				if len(elims) == 0 {
+					return false
+				}
+				step = &Step{
+					Technique:  meta.id,
+					Difficulty: meta.difficulty,
+					Message: fmt.Sprintf("Naked %s (%s) in %s at %s",
+						subsetNames[n], joinDigits(digitsOf(union)), unitName(u), joinCells(cells)),
+					Cells:        append([]int{}, cells...),
+					Eliminations: elims,
+				}
+				return true
+			})
+			if step != nil {
+				return step
+			}
+		}
+		return nil
+	}
+}
+
+// hiddenSubsetFinder finds n digits in a unit that are confined to exactly n cells.
+func hiddenSubsetFinder(n int) func(*Engine) *Step {
+	meta := hiddenSubsets[n]
+	return func(e *Engine) *Step {
+		for u := 0; u < 27; u++ {
+			var pool []int
+			var where [10][]int
+			for d := uint8(1); d <= 9; d++ {
+				cells := e.cellsWith(u, d)
+				if len(cells) >= 2 && len(cells) <= n {
+					pool = append(pool, int(d))
+					where[d] = cells
+				}
+			}
+			if len(pool) < n {
+				continue
+			}
+
+			var step *Step
+			combinations(pool, n, func(ds []int) bool {
+				var mask uint16
+				seen := map[int]bool{}
+				var cells []int
+				for _, d := range ds {
+					mask |= uint16(1) << d
+					for _, idx := range where[d] {
+						if !seen[idx] {
+							seen[idx] = true
+							cells = append(cells, idx)
+						}
+					}
+				}
+				if len(cells) != n {
+					return false
+				}
+				var elims []Elimination
+				for _, idx := range cells {
+					for _, d := range digitsOf(e.candidates[idx] &^ mask) {
+						elims = append(elims, Elimination{Cell: idx, Digit: d})
+					}
+				}
+				if len(elims) == 0 {
+					return false
+				}
+				step = &Step{
+					Technique:  meta.id,
+					Difficulty: meta.difficulty,
+					Message: fmt.Sprintf("Hidden %s (%s) in %s at %s",
+						subsetNames[n], joinDigits(digitsOf(mask)), unitName(u), joinCells(cells)),
+					Cells:        cells,
+					Eliminations: elims,
+				}
+				return true
+			})
+			if step != nil {
+				return step
+			}
+		}
+		return nil
+	}
+}
+
+func joinDigits(ds []uint8) string {
+	parts := make([]string, len(ds))
+	for i, d := range ds {
+		parts[i] = fmt.Sprint(d)
+	}
+	return strings.Join(parts, ",")
+}
+
+func joinCells(cells []int) string {
+	parts := make([]string, len(cells))
+	for i, idx := range cells {
+		parts[i] = cellName(idx)
+	}
+	return strings.Join(parts, ", ")
+}
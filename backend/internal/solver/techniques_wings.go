@@ -0,0 +1,120 @@
+package solver
+
+import "fmt"
+
+// findXYWing finds a bivalue pivot XY with pincers XZ and YZ; Z is removed from cells
+// that see both pincers.
+func findXYWing(e *Engine) *Step {
+	for p := 0; p < 81; p++ {
+		pm := e.candidates[p]
+		if bitsCount16(pm) != 2 {
+			continue
+		}
+		for _, a := range peers[p] {
+			am := e.candidates[a]
+			if bitsCount16(am) != 2 || bitsCount16(am&pm) != 1 {
+				continue
+			}
+			z := am &^ pm
+			for _, b := range peers[p] {
+				if b == a || e.candidates[b] != (pm&^am)|z {
+					continue
+				}
+				zd := digitsOf(z)[0]
+				elims := e.eliminationsFrom(commonPeers(a, b), zd, nil)
+				if len(elims) == 0 {
+					continue
+				}
+				return &Step{
+					Technique:  TechniqueXYWing,
+					Difficulty: 8,
+					Message: fmt.Sprintf("XY-Wing with pivot %s and pincers %s, %s: %d is eliminated from cells seeing both pincers",
+						cellName(p), cellName(a), cellName(b), zd),
+					Cells:        append([]int{p, a, b}, elimCells(elims)...),
+					Eliminations: elims,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findXYZWing finds a trivalue pivot XYZ with pincers XZ and YZ; Z is removed from cells
+// that see the pivot and both pincers.
+func findXYZWing(e *Engine) *Step {
+	for p := 0; p < 81; p++ {
+		pm := e.candidates[p]
+		if bitsCount16(pm) != 3 {
+			continue
+		}
+		for _, a := range peers[p] {
+			am := e.candidates[a]
+			if bitsCount16(am) != 2 || am&pm != am {
+				continue
+			}
+			for _, b := range peers[p] {
+				bm := e.candidates[b]
+				if b <= a || bitsCount16(bm) != 2 || bm&pm != bm || bm == am {
+					continue
+				}
+				zd := digitsOf(am & bm)[0]
+				elims := e.eliminationsFrom(commonPeers(p, a, b), zd, nil)
+				if len(elims) == 0 {
+					continue
+				}
+				return &Step{
+					Technique:  TechniqueXYZWing,
+					Difficulty: 9,
+					Message: fmt.Sprintf("XYZ-Wing with pivot %s and pincers %s, %s: %d is eliminated from cells seeing all three",
+						cellName(p), cellName(a), cellName(b), zd),
+					Cells:        append([]int{p, a, b}, elimCells(elims)...),
+					Eliminations: elims,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findWWing finds two identical bivalue cells XY joined by a strong link on X; Y is removed
+// from cells that see both.
+func findWWing(e *Engine) *Step {
+	for a := 0; a < 81; a++ {
+		m := e.candidates[a]
+		if bitsCount16(m) != 2 {
+			continue
+		}
+		for b := a + 1; b < 81; b++ {
+			if e.candidates[b] != m || sees(a, b) {
+				continue
+			}
+			ds := digitsOf(m)
+			for i, x := range ds {
+				y := ds[1-i]
+				elims := e.eliminationsFrom(commonPeers(a, b), y, nil)
+				if len(elims) == 0 {
+					continue
+				}
+				for u := 0; u < 27; u++ {
+					link := e.cellsWith(u, x)
+					if len(link) != 2 || link[0] == a || link[0] == b || link[1] == a || link[1] == b {
+						continue
+					}
+					s1, s2 := link[0], link[1]
+					if !(sees(s1, a) && sees(s2, b)) && !(sees(s1, b) && sees(s2, a)) {
+						continue
+					}
+					return &Step{
+						Technique:  TechniqueWWing,
+						Difficulty: 8,
+						Message: fmt.Sprintf("W-Wing on %s and %s linked by %d in %s: %d is eliminated from cells seeing both",
+							cellName(a), cellName(b), x, unitName(u), y),
+						Cells:        append([]int{a, b, s1, s2}, elimCells(elims)...),
+						Eliminations: elims,
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package solver
+
+import (
+	"context"
+	"errors"
+)
+
+// Variant holds the rules a puzzle adds on top of classic Sudoku. The zero value is
+// classic Sudoku.
+type Variant struct {
+	// Rules are names of registered constraints, e.g. RuleAntiKnight.
+	Rules []string `json:"rules,omitempty"`
+	Cages []Cage   `json:"cages,omitempty"`
+	// Regions is a normalized jigsaw region map (see NormalizeRegions) replacing the
+	// 3x3 boxes; empty means classic boxes.
+	Regions string `json:"regions,omitempty"`
+	// Geometry holds line constraints such as thermometers and arrows.
+	Geometry Geometry `json:"geometry"`
+}
+
+// IsClassic reports whether the variant adds no rules. The technique engine, the rating
+// and the hints only understand classic rules.
+func (v Variant) IsClassic() bool {
+	return len(v.Rules) == 0 && len(v.Cages) == 0 && v.Regions == "" && v.Geometry.IsEmpty()
+}
+
+// HasClassicBoxes reports whether the 3x3 boxes are units. Classic deductions (and so
+// hints) stay sound under extra rules, but not once the boxes are replaced.
+func (v Variant) HasClassicBoxes() bool {
+	return v.Regions == ""
+}
+
+// Validate checks the variant's rules against the givens.
+func (v Variant) Validate(g Grid) error {
+	lay, err := v.layout()
+	if err != nil {
+		return err
+	}
+	kind := "box"
+	if !v.HasClassicBoxes() {
+		kind = "region"
+	}
+	if err := lay.conflict(g, kind); err != nil {
+		return err
+	}
+	if err := ValidateCages(g, v.Cages); err != nil {
+		return err
+	}
+	constraints, err := v.constraints()
+	if err != nil {
+		return err
+	}
+	for _, c := range constraints {
+		if err := checkConstraint(g, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountSolutions counts the solutions that satisfy the variant, up to limit.
+func (v Variant) CountSolutions(g Grid, limit int) (int, error) {
+	return v.CountSolutionsContext(context.Background(), g, limit)
+}
+
+// FindSolutions returns up to limit solutions that satisfy the variant.
+func (v Variant) FindSolutions(g Grid, limit int) ([]Grid, error) {
+	return v.FindSolutionsContext(context.Background(), g, limit)
+}
+
+// CountSolutionsContext is CountSolutions, stopping with ErrTimeout once ctx is done.
+func (v Variant) CountSolutionsContext(ctx context.Context, g Grid, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, errors.New("invalid_limit")
+	}
+	lay, err := v.layout()
+	if err != nil {
+		return 0, err
+	}
+	constraints, err := v.constraints()
+	if err != nil {
+		return 0, err
+	}
+	if err := v.Validate(g); err != nil {
+		return 0, nil
+	}
+	if v.IsClassic() {
+		return BitboardSolver{}.countContext(ctx, g, limit)
+	}
+
+	return search(ctx, g, lay, constraints, limit, nil)
+}
+
+// FindSolutionsContext is FindSolutions, stopping with ErrTimeout once ctx is done.
+func (v Variant) FindSolutionsContext(ctx context.Context, g Grid, limit int) ([]Grid, error) {
+	if limit <= 0 {
+		return nil, errors.New("invalid_limit")
+	}
+	lay, err := v.layout()
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := v.constraints()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.Validate(g); err != nil {
+		return nil, nil
+	}
+	if v.IsClassic() {
+		return BitboardSolver{}.findContext(ctx, g, limit)
+	}
+
+	var solutions []Grid
+	_, err = search(ctx, g, lay, constraints, limit, func(solution Grid) {
+		solutions = append(solutions, solution)
+	})
+	return solutions, err
+}
+
+// constraints returns the variant's rules as constraints for the search.
+func (v Variant) constraints() ([]Constraint, error) {
+	// Lines and cages index cells directly, so check them before building constraints.
+	if err := ValidateGeometry(v.Geometry); err != nil {
+		return nil, err
+	}
+	if err := ValidateCages(Grid{}, v.Cages); err != nil {
+		return nil, err
+	}
+	var out []Constraint
+	for _, name := range v.Rules {
+		c, ok := rules[name]
+		if !ok {
+			return nil, errors.New("unknown_rule")
+		}
+		out = append(out, c)
+	}
+	if len(v.Cages) > 0 {
+		out = append(out, newCageIndex(v.Cages))
+	}
+	return append(out, v.Geometry.constraints()...), nil
+}
+
+// layout returns the classic layout, or the one built from the jigsaw regions.
+func (v Variant) layout() (*layout, error) {
+	if v.Regions == "" {
+		return &classicLayout, nil
+	}
+	regions, err := NormalizeRegions(v.Regions)
+	if err != nil {
+		return nil, err
+	}
+	return newRegionLayout(regions), nil
+}